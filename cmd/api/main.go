@@ -8,15 +8,19 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Timezones in user settings are validated without system zoneinfo
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"notifications/internal/auth"
 	"notifications/internal/config"
 	apihttp "notifications/internal/http"
 	"notifications/internal/logger"
 	"notifications/internal/queue"
 	"notifications/internal/repo"
+	"notifications/internal/stream"
 )
 
 func main() {
@@ -53,13 +57,35 @@ func main() {
 
 	// Initialize queue client
 	appLogger.Info("connecting to Redis queue")
-	queueClient := queue.NewClient(cfg.RedisAddr)
+	retryPolicies := queue.NewRetryPolicies(cfg.RetryMaxRetry, cfg.RetryBaseDelay, cfg.RetryMaxDelay)
+	queueClient := queue.NewClient(cfg.RedisAddr, retryPolicies)
 	defer queueClient.Close()
 
+	inspector := queue.NewInspector(cfg.RedisAddr)
+	defer inspector.Close()
+
 	appLogger.Info("queue client initialized")
 
+	// Relay inbox events from the worker to open streams
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	defer rdb.Close()
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	defer stopHub()
+	hub := stream.NewHub(rdb, appLogger)
+	go hub.Run(hubCtx)
+
+	// API client secrets are encrypted at rest; without a key only the shared
+	// secret authenticates
+	var secretCipher *auth.SecretCipher
+	if cfg.APIClientSecretKey != "" {
+		secretCipher, err = auth.NewSecretCipher(cfg.APIClientSecretKey)
+		if err != nil {
+			appLogger.Fatal("failed to initialize api client secret cipher", zap.Error(err))
+		}
+	}
+
 	// Create HTTP router
-	router := apihttp.NewRouter(*cfg, repository, queueClient, appLogger)
+	router := apihttp.NewRouter(*cfg, repository, queueClient, inspector, hub, secretCipher, appLogger)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"notifications/internal/auth"
 	"notifications/internal/repo"
 
 	"github.com/google/uuid"
@@ -198,7 +199,10 @@ func testNotifications(ctx context.Context, r *repo.Repository) {
 
 	// Get by idempotency key
 	log.Println("   Retrieving notification by idempotency key...")
-	byKey, err := r.GetNotificationByIdempotencyKey(ctx, notif.IdempotencyKey)
+	byKey, err := r.GetNotificationByIdempotencyKey(ctx, repo.GetNotificationByIdempotencyKeyParams{
+		CreatedBy:      auth.SharedKeyID,
+		IdempotencyKey: *notif.IdempotencyKey,
+	})
 	if err != nil {
 		log.Fatalf("❌ Failed to get notification by idempotency key: %v", err)
 	}
@@ -43,7 +43,7 @@ func main() {
 
 	hmacSecret = cfg.HMACSecret
 
-	fmt.Println("\n=== Phase 4 API Testing ===\n")
+	fmt.Print("\n=== Phase 4 API Testing ===\n\n")
 
 	// Test 1: Health Check (no auth)
 	test("Health Check", testHealthCheck)
@@ -12,6 +12,7 @@ import (
 	"notifications/internal/config"
 	"notifications/internal/queue"
 	"notifications/internal/repo"
+	"notifications/internal/templates"
 	"notifications/internal/webpush"
 )
 
@@ -41,13 +42,13 @@ func main() {
 
 	// 2. Initialize queue client
 	fmt.Println("2. Connecting to Redis queue...")
-	queueClient := queue.NewClient(cfg.RedisAddr)
+	queueClient := queue.NewClient(cfg.RedisAddr, queue.DefaultRetryPolicies())
 	defer queueClient.Close()
 	fmt.Println("   ✓ Connected to Redis")
 
 	// 3. Initialize webpush sender
 	fmt.Println("3. Initializing webpush sender...")
-	sender := webpush.NewSender(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, repository)
+	sender := webpush.NewSender(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, repository, templates.NewResolver(cfg.DefaultLocale, repository), nil)
 	fmt.Println("   ✓ Webpush sender initialized")
 
 	// 4. Create test notification
@@ -93,6 +94,7 @@ func main() {
 		sub.ID,
 		"normal",
 		3600,
+		time.Time{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to enqueue task: %v", err)
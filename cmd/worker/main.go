@@ -1,19 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Subscription timezones must resolve without system zoneinfo
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 
+	"notifications/internal/auth"
+	"notifications/internal/callback"
+	"notifications/internal/channel"
 	"notifications/internal/config"
+	"notifications/internal/email"
 	"notifications/internal/logger"
 	"notifications/internal/queue"
 	"notifications/internal/repo"
+	"notifications/internal/stream"
+	"notifications/internal/templates"
+	"notifications/internal/tracking"
+	"notifications/internal/webhook"
 	"notifications/internal/webpush"
 )
 
@@ -53,9 +64,61 @@ func main() {
 
 	slogger.Info("Connected to database")
 
-	// Initialize webpush sender
-	sender := webpush.NewSender(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, repository)
-	slogger.Info("Initialized webpush sender")
+	defaultTimezone, err := time.LoadLocation(cfg.DefaultTimezone)
+	if err != nil {
+		slogger.Error("Invalid DEFAULT_TIMEZONE", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Initialize channel senders
+	resolver := templates.NewResolver(cfg.DefaultLocale, repository)
+	var tracker *tracking.Signer
+	if cfg.TrackingBaseURL != "" {
+		tracker = tracking.NewSigner(cfg.TrackingSecret, cfg.TrackingBaseURL, cfg.AppBaseURL)
+	}
+	senders := map[string]channel.Sender{
+		channel.Push: webpush.NewSender(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, repository, resolver, tracker),
+	}
+	slogger.Info("Initialized webpush sender", slog.Bool("tracking", tracker != nil))
+
+	if cfg.SMTPHost != "" {
+		emailSender, err := email.NewSender(email.Config{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}, repository, resolver)
+		if err != nil {
+			slogger.Error("Invalid SMTP configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		senders[channel.Email] = emailSender
+		slogger.Info("Initialized email sender", slog.String("smtp_host", cfg.SMTPHost))
+	}
+
+	senders[channel.Webhook] = webhook.NewSender(repository, resolver, cfg.WebhookTimeout)
+	slogger.Info("Initialized webhook sender")
+
+	// Callbacks for API clients' notifications are signed with the client's
+	// own secret, which is stored encrypted
+	var secretCipher *auth.SecretCipher
+	if cfg.APIClientSecretKey != "" {
+		secretCipher, err = auth.NewSecretCipher(cfg.APIClientSecretKey)
+		if err != nil {
+			slogger.Error("Invalid API_CLIENT_SECRET_KEY", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	// Initialize queue client (fan-out enqueues per-subscription deliveries)
+	retryPolicies := queue.NewRetryPolicies(cfg.RetryMaxRetry, cfg.RetryBaseDelay, cfg.RetryMaxDelay)
+	queueClient := queue.NewClient(cfg.RedisAddr, retryPolicies)
+	defer queueClient.Close()
+
+	// Redis pub/sub carries inbox events to the API's open streams
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	defer rdb.Close()
 
 	// Initialize worker
 	worker := queue.NewWorker(
@@ -67,9 +130,17 @@ func main() {
 				"default": 3, // Priority weight 3
 				"low":     1, // Priority weight 1
 			},
+			RetryPolicies: retryPolicies,
+			DedupeWindow:  cfg.DedupeWindow,
+			DedupeWindows: cfg.DedupeWindows,
+
+			DefaultTimezone: defaultTimezone,
 		},
 		repository,
-		sender,
+		senders,
+		queueClient,
+		stream.NewPublisher(rdb),
+		callback.NewSender(cfg.HMACSecret, repository, secretCipher, cfg.CallbackTimeout),
 		slogger,
 	)
 
@@ -81,6 +152,24 @@ func main() {
 
 	slogger.Info("Worker started successfully")
 
+	// Start outbox relay (publishes notifications committed by the API)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	relay := queue.NewOutboxRelay(
+		queue.OutboxRelayConfig{
+			PollInterval: cfg.OutboxPollInterval,
+			BatchSize:    cfg.OutboxBatchSize,
+			Retention:    cfg.OutboxRetention,
+		},
+		repository,
+		queueClient,
+		slogger,
+	)
+	go func() {
+		defer close(relayDone)
+		relay.Run(relayCtx)
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -89,6 +178,8 @@ func main() {
 	slogger.Info("Shutting down worker...")
 
 	// Graceful shutdown
+	stopRelay()
+	<-relayDone
 	worker.Stop()
 
 	// Give time for workers to finish
@@ -0,0 +1,23 @@
+-- notification_templates: database-managed, versioned copy per type and locale.
+-- Versions are immutable; editing a template creates a new version.
+CREATE TABLE IF NOT EXISTS notification_templates (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  type text NOT NULL,
+  locale text NOT NULL,
+  version integer NOT NULL,
+  title text NOT NULL,
+  body text NOT NULL,
+  url text,
+  icon text,
+  is_active boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (type, locale, version)
+);
+-- At most one active version per type and locale
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_active ON notification_templates(type, locale) WHERE is_active;
+
+-- notifications: record the template version pinned at creation
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS template_id uuid REFERENCES notification_templates(id);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS template_version integer;
+CREATE INDEX IF NOT EXISTS idx_notifications_template ON notifications(template_id);
@@ -0,0 +1,16 @@
+-- notification_outbox: tasks written in the same transaction as the
+-- notification and published to the queue by the worker's outbox relay.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  notification_id uuid NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+  task_type text NOT NULL,
+  payload jsonb NOT NULL,
+  queue text NOT NULL DEFAULT 'default',
+  status text NOT NULL DEFAULT 'pending', -- pending|dispatched
+  attempts integer NOT NULL DEFAULT 0,
+  last_error text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  dispatched_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending ON notification_outbox(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_notification ON notification_outbox(notification_id);
@@ -0,0 +1,8 @@
+-- notifications: aggregate delivery lifecycle.
+-- status moves queued -> sending -> sent|partial|failed; expected_deliveries is
+-- set by fan-out and completed_at once every delivery reached a terminal outcome.
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS expected_deliveries integer;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS completed_at timestamptz;
+
+-- notification_attempts: latest outcome per subscription, used by the status rollup
+CREATE INDEX IF NOT EXISTS idx_attempts_notification_subscription ON notification_attempts(notification_id, subscription_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- notification_attempts: why a delivery was skipped (e.g. dedupe)
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS reason text;
+
+-- notifications: dedupe lookups by key within a time window
+CREATE INDEX IF NOT EXISTS idx_notifications_dedupe_created ON notifications(dedupe_key, created_at DESC) WHERE dedupe_key IS NOT NULL;
@@ -0,0 +1,9 @@
+-- notifications: canonical request hash for idempotency conflict detection,
+-- and when the idempotency key is released for reuse.
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS request_hash text;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS idempotency_expires_at timestamptz;
+
+-- Existing keys expire after the default retention (24h)
+UPDATE notifications
+SET idempotency_expires_at = created_at + interval '24 hours'
+WHERE idempotency_key IS NOT NULL AND idempotency_expires_at IS NULL;
@@ -0,0 +1,6 @@
+-- notifications: optional future send time. Scheduled notifications stay
+-- 'scheduled' until fan-out runs, or become 'cancelled' when cancelled first.
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS send_at timestamptz;
+
+-- notification_outbox: tasks published with asynq ProcessAt run no earlier than process_at
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS process_at timestamptz;
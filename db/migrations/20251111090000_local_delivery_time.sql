@@ -0,0 +1,3 @@
+-- notifications: optional wall-clock time (HH:MM) at which each device
+-- receives the notification in its subscription's timezone
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS deliver_local_time text;
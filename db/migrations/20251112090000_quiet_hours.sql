@@ -0,0 +1,12 @@
+-- user_quiet_hours: per-user do-not-disturb window (HH:MM, wrapping past
+-- midnight when start_time > end_time). Non-critical deliveries that fall in
+-- the window are deferred until it ends.
+CREATE TABLE IF NOT EXISTS user_quiet_hours (
+  user_id text PRIMARY KEY,
+  start_time text NOT NULL,
+  end_time text NOT NULL,
+  timezone text,
+  enabled boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
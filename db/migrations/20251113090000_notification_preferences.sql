@@ -0,0 +1,11 @@
+-- user_notification_preferences: per-user opt-outs by notification type.
+-- type_pattern is an exact type, a prefix ending in ".*", or "*"; the most
+-- specific matching pattern wins and types with no match are enabled.
+CREATE TABLE IF NOT EXISTS user_notification_preferences (
+  user_id text NOT NULL,
+  type_pattern text NOT NULL,
+  enabled boolean NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, type_pattern)
+);
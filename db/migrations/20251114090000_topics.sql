@@ -0,0 +1,19 @@
+-- notification_topics: named audiences, e.g. warehouse.central
+CREATE TABLE IF NOT EXISTS notification_topics (
+  name text PRIMARY KEY,
+  description text,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- topic_subscriptions: users subscribed to a topic. The primary key serves
+-- fan-out, which pages through members in user_id order.
+CREATE TABLE IF NOT EXISTS topic_subscriptions (
+  topic text NOT NULL REFERENCES notification_topics(name) ON DELETE CASCADE,
+  user_id text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (topic, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_topic_subscriptions_user ON topic_subscriptions(user_id);
+
+-- notifications: topics whose members receive the notification, in addition to its recipients
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS topics text[];
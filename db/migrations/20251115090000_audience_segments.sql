@@ -0,0 +1,8 @@
+-- device_subscriptions: attributes from registration (e.g. role, warehouse)
+-- used to target audience segments
+ALTER TABLE device_subscriptions ADD COLUMN IF NOT EXISTS metadata jsonb NOT NULL DEFAULT '{}'::jsonb;
+CREATE INDEX IF NOT EXISTS idx_device_subscriptions_metadata ON device_subscriptions USING gin (metadata jsonb_path_ops) WHERE is_active;
+CREATE INDEX IF NOT EXISTS idx_device_subscriptions_locale ON device_subscriptions(locale, user_id) WHERE is_active;
+
+-- notifications: segment filter whose matching subscriptions receive the notification
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS segment jsonb;
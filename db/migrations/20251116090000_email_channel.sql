@@ -0,0 +1,21 @@
+-- user_contact_points: addresses for channels other than web push (e.g. email)
+CREATE TABLE IF NOT EXISTS user_contact_points (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id text NOT NULL,
+  channel text NOT NULL,
+  address text NOT NULL,
+  locale text,
+  is_active boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (user_id, channel, address)
+);
+CREATE INDEX IF NOT EXISTS idx_contact_points_user_channel ON user_contact_points(user_id, channel) WHERE is_active;
+
+-- notifications: channels the notification is delivered on (NULL means push only)
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS channels text[];
+
+-- notification_attempts: channel used, and the contact point for non-push channels
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS channel text NOT NULL DEFAULT 'push';
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS contact_point_id uuid REFERENCES user_contact_points(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_attempts_contact_point ON notification_attempts(contact_point_id);
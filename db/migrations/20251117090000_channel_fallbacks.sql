@@ -0,0 +1,30 @@
+-- notification_routing_policies: per-type fallback when push reaches nobody
+CREATE TABLE IF NOT EXISTS notification_routing_policies (
+  type text PRIMARY KEY,
+  fallback_channel text NOT NULL,
+  fallback_delay_seconds integer NOT NULL DEFAULT 0,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- notification_fallbacks: one per recipient a policy applies to, triggered
+-- once every push delivery to the recipient failed, was pruned or skipped.
+-- deliveries counts towards the notification's expected deliveries.
+CREATE TABLE IF NOT EXISTS notification_fallbacks (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  notification_id uuid NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+  user_id text NOT NULL,
+  channel text NOT NULL,
+  delay_seconds integer NOT NULL DEFAULT 0,
+  expected_push integer NOT NULL,
+  status text NOT NULL DEFAULT 'pending',
+  deliveries integer NOT NULL DEFAULT 0,
+  process_at timestamptz,
+  triggered_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (notification_id, user_id)
+);
+
+-- notification_attempts: fallback the attempt was sent for
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS fallback_id uuid REFERENCES notification_fallbacks(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_attempts_notification_user ON notification_attempts(notification_id, user_id);
@@ -0,0 +1,18 @@
+-- user_contact_points: signing secret for webhook contact points (address is the URL)
+ALTER TABLE user_contact_points ADD COLUMN IF NOT EXISTS secret text;
+
+-- topic_webhooks: HTTPS endpoints that receive every webhook notification sent to a topic
+CREATE TABLE IF NOT EXISTS topic_webhooks (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  topic text NOT NULL REFERENCES notification_topics(name) ON DELETE CASCADE,
+  url text NOT NULL,
+  secret text NOT NULL,
+  is_active boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (topic, url)
+);
+
+-- notification_attempts: topic webhook the attempt was sent to (user_id is empty)
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS topic_webhook_id uuid REFERENCES topic_webhooks(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_attempts_topic_webhook ON notification_attempts(topic_webhook_id);
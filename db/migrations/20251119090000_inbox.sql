@@ -0,0 +1,12 @@
+-- inbox_items: each recipient's in-app copy of a notification, created at fan-out
+CREATE TABLE IF NOT EXISTS inbox_items (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id text NOT NULL,
+  notification_id uuid NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+  read_at timestamptz,
+  archived_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (notification_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_inbox_items_user_created ON inbox_items(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_inbox_items_user_unread ON inbox_items(user_id) WHERE read_at IS NULL AND archived_at IS NULL;
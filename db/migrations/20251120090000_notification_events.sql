@@ -0,0 +1,13 @@
+-- notification_events: engagement reported by recipients' clients, as opposed
+-- to notification_attempts, which record what the provider accepted. Each
+-- recipient reports each event once per notification.
+CREATE TABLE IF NOT EXISTS notification_events (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  notification_id uuid NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+  user_id text NOT NULL,
+  event text NOT NULL CHECK (event IN ('delivered', 'displayed', 'clicked')),
+  source text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (notification_id, user_id, event)
+);
+CREATE INDEX IF NOT EXISTS idx_notification_events_user ON notification_events(user_id, created_at DESC);
@@ -0,0 +1,4 @@
+-- Service workers also report when a push notification is closed without a click
+ALTER TABLE notification_events DROP CONSTRAINT IF EXISTS notification_events_event_check;
+ALTER TABLE notification_events ADD CONSTRAINT notification_events_event_check
+  CHECK (event IN ('delivered', 'displayed', 'clicked', 'closed'));
@@ -0,0 +1,4 @@
+-- Producers can ask for signed status callbacks: one when the notification
+-- reaches a terminal state and, with callback_attempts, one per delivery attempt
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS callback_url text;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS callback_attempts boolean NOT NULL DEFAULT false;
@@ -0,0 +1,24 @@
+-- api_clients: producers calling the API, each signing requests with one of
+-- its own secrets instead of the shared HMAC_SECRET
+CREATE TABLE IF NOT EXISTS api_clients (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  key_id text NOT NULL UNIQUE,
+  name text NOT NULL,
+  scopes text[] NOT NULL DEFAULT '{}',
+  status text NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'disabled')),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- api_client_secrets: SHA-256 digests of a client's secrets. A client may
+-- hold several while rotating.
+CREATE TABLE IF NOT EXISTS api_client_secrets (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  client_id uuid NOT NULL REFERENCES api_clients(id) ON DELETE CASCADE,
+  secret_hash text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_api_client_secrets_client ON api_client_secrets(client_id);
+
+-- created_by: key ID of the client that sent the notification
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS created_by text;
@@ -0,0 +1,10 @@
+-- notification_dedupe_claims: the notification that holds a user's dedupe_key
+-- until expires_at. Fan-out claims the key before delivering, so concurrent
+-- sends with the same key can't both pass the dedupe check.
+CREATE TABLE IF NOT EXISTS notification_dedupe_claims (
+  user_id text NOT NULL,
+  dedupe_key text NOT NULL,
+  notification_id uuid NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+  expires_at timestamptz NOT NULL,
+  PRIMARY KEY (user_id, dedupe_key)
+);
@@ -0,0 +1,5 @@
+-- notification_attempts: the template version each delivery was rendered
+-- from, since recipients in other locales than the pinned one may get a
+-- different template
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS template_id uuid REFERENCES notification_templates(id);
+ALTER TABLE notification_attempts ADD COLUMN IF NOT EXISTS template_version integer;
@@ -0,0 +1,3 @@
+-- callback_url: where callbacks for a client's notifications go when the
+-- notification names none, ahead of DEFAULT_CALLBACK_URL
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS callback_url text;
@@ -0,0 +1,6 @@
+-- notification_dedupe_claims: the suppression window, so a claim can be
+-- renewed from the time the notification was actually delivered. Claims of
+-- recipients that received nothing are released when the notification
+-- completes.
+ALTER TABLE notification_dedupe_claims ADD COLUMN IF NOT EXISTS window_seconds integer NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_dedupe_claims_notification ON notification_dedupe_claims(notification_id);
@@ -0,0 +1,6 @@
+-- api_client_secrets: secrets are encrypted with API_CLIENT_SECRET_KEY
+-- (AES-256-GCM, base64 nonce and ciphertext) rather than hashed, since
+-- verifying and producing HMAC signatures needs the raw secret. Digests stored
+-- before this can't be recovered and no longer authenticate; rotate those
+-- clients' secrets.
+ALTER TABLE api_client_secrets RENAME COLUMN secret_hash TO secret_ciphertext;
@@ -0,0 +1,6 @@
+-- notifications: idempotency keys are unique per producer, so two API clients
+-- may use the same key. Notifications without created_by were sent with the
+-- shared secret and share its scope.
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_idempotency_key_key;
+DROP INDEX IF EXISTS idx_notifications_idempotency;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_client_idempotency ON notifications(COALESCE(created_by, 'shared'), idempotency_key);
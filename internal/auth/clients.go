@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Scopes granted to API clients
+const (
+	ScopeAll                = "*"
+	ScopeNotificationsWrite = "notifications:write" // Send and cancel notifications
+	ScopeNotificationsRead  = "notifications:read"  // Notifications, attempts and analytics
+	ScopeUsers              = "users"               // Subscriptions, user settings and inboxes
+	ScopeConfig             = "config"              // Topics, routing policies and templates
+	ScopeAdmin              = "admin"               // Dead-letter queue and API clients
+)
+
+// Scopes lists every scope a client can be granted
+var Scopes = []string{
+	ScopeAll,
+	ScopeNotificationsWrite,
+	ScopeNotificationsRead,
+	ScopeUsers,
+	ScopeConfig,
+	ScopeAdmin,
+}
+
+// SharedKeyID identifies requests signed with the shared HMAC secret rather
+// than a client's own. Generated key IDs never collide with it.
+const SharedKeyID = "shared"
+
+// ErrClientNotFound is returned by ClientStore for unknown key IDs
+var ErrClientNotFound = errors.New("api client not found")
+
+// Client is the authenticated caller of a request. CallbackURL, if set, is
+// where callbacks for its notifications go by default.
+type Client struct {
+	KeyID       string
+	Name        string
+	Scopes      []string
+	CallbackURL string
+}
+
+// HasScope reports whether the client was granted scope, directly or via "*"
+func (c Client) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == ScopeAll || s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type clientKey struct{}
+
+// WithClient returns a copy of ctx carrying the client
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client VerifyClientMiddleware authenticated
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientKey{}).(Client)
+	return client, ok
+}
+
+// ClientStore looks up API clients by key ID. keys are the client's current
+// secrets; active is false for disabled clients.
+type ClientStore interface {
+	LookupClient(ctx context.Context, keyID string) (client Client, active bool, keys [][]byte, err error)
+}
+
+// NewKeyID generates a public key ID for a new client
+func NewKeyID() (string, error) {
+	return randomToken("ak_", 12)
+}
+
+// NewSecret generates a client secret. It is shown once and stored
+// encrypted with a SecretCipher.
+func NewSecret() (string, error) {
+	return randomToken("sk_", 32)
+}
+
+func randomToken(prefix string, n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// VerifyClientMiddleware authenticates requests by X-Key-ID, X-Timestamp and
+// X-Signature, where the signature is Sign keyed with the client's secret; any
+// of the client's secrets is accepted, so secrets can be rotated without
+// downtime. The client is added to the request context. Requests without
+// X-Key-ID are verified against sharedSecret as the SharedKeyID client with
+// every scope, unless sharedSecret is empty.
+func VerifyClientMiddleware(store ClientStore, sharedSecret []byte, maxSkew time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keyID := r.Header.Get("X-Key-ID")
+			ts := r.Header.Get("X-Timestamp")
+			sig := r.Header.Get("X-Signature")
+			if ts == "" || sig == "" {
+				http.Error(w, "missing signature", http.StatusUnauthorized)
+				return
+			}
+			pt, err := time.Parse(time.RFC3339, ts)
+			if err != nil || time.Since(pt) > maxSkew || time.Until(pt) > maxSkew {
+				http.Error(w, "invalid timestamp", http.StatusUnauthorized)
+				return
+			}
+
+			var client Client
+			var keys [][]byte
+			if keyID == "" {
+				if len(sharedSecret) == 0 {
+					http.Error(w, "missing key id", http.StatusUnauthorized)
+					return
+				}
+				client = Client{KeyID: SharedKeyID, Name: SharedKeyID, Scopes: []string{ScopeAll}}
+				keys = [][]byte{sharedSecret}
+			} else {
+				var active bool
+				client, active, keys, err = store.LookupClient(r.Context(), keyID)
+				if errors.Is(err, ErrClientNotFound) {
+					http.Error(w, "unknown key id", http.StatusUnauthorized)
+					return
+				}
+				if err != nil {
+					http.Error(w, "internal server error", http.StatusInternalServerError)
+					return
+				}
+				if !active {
+					http.Error(w, "client disabled", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "bad body", http.StatusBadRequest)
+				return
+			}
+			_ = r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if !verifyAny(keys, r.Method, r.URL.Path, body, ts, sig) {
+				http.Error(w, "bad signature", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithClient(r.Context(), client)))
+		})
+	}
+}
+
+func verifyAny(keys [][]byte, method, path string, body []byte, timestamp, providedSig string) bool {
+	for _, key := range keys {
+		if Verify(key, method, path, body, timestamp, providedSig) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope rejects requests whose client lacks scope. It must run after
+// VerifyClientMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, ok := ClientFromContext(r.Context())
+			if !ok || !client.HasScope(scope) {
+				http.Error(w, "insufficient scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCiphertext is returned for stored secrets that weren't encrypted
+// with this service's key or were altered
+var ErrInvalidCiphertext = errors.New("invalid secret ciphertext")
+
+// SecretCipher encrypts client secrets at rest with AES-256-GCM. Requests are
+// signed with the raw secret, so the service must be able to recover it; the
+// database alone is not enough to forge a signature.
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher creates a cipher from a base64-encoded 32-byte key
+func NewSecretCipher(key string) (*SecretCipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(raw))
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{aead: aead}, nil
+}
+
+// Encrypt returns the base64 nonce and ciphertext stored for a secret
+func (c *SecretCipher) Encrypt(secret string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// Decrypt recovers a secret from what Encrypt returned
+func (c *SecretCipher) Decrypt(ciphertext string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	secret, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return secret, nil
+}
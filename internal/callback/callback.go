@@ -0,0 +1,268 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"notifications/internal/auth"
+	"notifications/internal/channel"
+	"notifications/internal/repo"
+)
+
+// Callback events
+const (
+	EventNotificationCompleted = "notification.completed" // Sent, partial, failed or suppressed
+	EventNotificationCancelled = "notification.cancelled"
+	EventAttemptCreated        = "attempt.created"
+)
+
+// Payload is the JSON body POSTed to callback URLs. Notification events
+// carry Status; attempt events carry Attempt.
+type Payload struct {
+	Event          string     `json:"event"`
+	NotificationID uuid.UUID  `json:"notification_id"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	Counts         *Counts    `json:"counts,omitempty"`
+	Attempt        *Attempt   `json:"attempt,omitempty"`
+}
+
+// Counts holds the latest delivery outcome per target, by status
+type Counts struct {
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+	Pruned    int `json:"pruned"`
+	Skipped   int `json:"skipped"`
+}
+
+// Attempt describes a delivery attempt, like GET /v1/notifications/:id/attempts
+type Attempt struct {
+	ID              uuid.UUID  `json:"id"`
+	UserID          string     `json:"user_id,omitempty"`
+	Channel         string     `json:"channel"`
+	SubscriptionID  *uuid.UUID `json:"subscription_id,omitempty"`
+	ContactPointID  *uuid.UUID `json:"contact_point_id,omitempty"`
+	TopicWebhookID  *uuid.UUID `json:"topic_webhook_id,omitempty"`
+	FallbackID      *uuid.UUID `json:"fallback_id,omitempty"`
+	Status          string     `json:"status"`
+	Reason          *string    `json:"reason,omitempty"`
+	HTTPStatus      *int32     `json:"http_status,omitempty"`
+	Error           *string    `json:"error,omitempty"`
+	RetryCount      *int32     `json:"retry_count,omitempty"`
+	TemplateID      *uuid.UUID `json:"template_id,omitempty"`
+	TemplateVersion *int32     `json:"template_version,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// NotificationPayload builds the callback for a notification that reached a
+// terminal status. counts is nil for cancelled notifications.
+func NotificationPayload(notif repo.Notification, counts *repo.GetNotificationDeliveryCountsRow) Payload {
+	event := EventNotificationCompleted
+	if notif.Status == "cancelled" {
+		event = EventNotificationCancelled
+	}
+	payload := Payload{
+		Event:          event,
+		NotificationID: notif.ID,
+		Type:           notif.Type,
+		Status:         notif.Status,
+		CompletedAt:    notif.CompletedAt,
+	}
+	if counts != nil {
+		payload.Counts = &Counts{
+			Delivered: int(counts.Delivered),
+			Failed:    int(counts.Failed),
+			Pruned:    int(counts.Pruned),
+			Skipped:   int(counts.Skipped),
+		}
+	}
+	return payload
+}
+
+// AttemptPayload builds the callback for a recorded delivery attempt
+func AttemptPayload(notificationType string, attempt repo.NotificationAttempt) Payload {
+	return Payload{
+		Event:          EventAttemptCreated,
+		NotificationID: attempt.NotificationID,
+		Type:           notificationType,
+		Attempt: &Attempt{
+			ID:              attempt.ID,
+			UserID:          attempt.UserID,
+			Channel:         attempt.Channel,
+			SubscriptionID:  uuidPtr(attempt.SubscriptionID),
+			ContactPointID:  uuidPtr(attempt.ContactPointID),
+			TopicWebhookID:  uuidPtr(attempt.TopicWebhookID),
+			FallbackID:      uuidPtr(attempt.FallbackID),
+			Status:          attempt.Status,
+			Reason:          attempt.Reason,
+			HTTPStatus:      attempt.HttpStatus,
+			Error:           attempt.Error,
+			RetryCount:      attempt.RetryCount,
+			TemplateID:      uuidPtr(attempt.TemplateID),
+			TemplateVersion: attempt.TemplateVersion,
+			CreatedAt:       attempt.CreatedAt,
+		},
+	}
+}
+
+func uuidPtr(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	u := uuid.UUID(id.Bytes)
+	return &u
+}
+
+// ErrKeyUnavailable is returned when the API client that created a
+// notification is disabled or has no secret to sign its callbacks with
+var ErrKeyUnavailable = errors.New("callback signing key unavailable")
+
+// Sender POSTs callbacks. Each request carries X-Timestamp and X-Signature
+// headers computed with auth.Sign, the same scheme producers sign their API
+// requests with. Callbacks for a notification created by an API client are
+// keyed with that client's newest secret and name it in X-Key-ID; the rest
+// are keyed with the service's shared HMAC secret.
+type Sender struct {
+	secret []byte
+	repo   *repo.Repository
+	cipher *auth.SecretCipher
+	client *http.Client
+}
+
+// NewSender creates a new callback sender. Client secrets are loaded from
+// repository and decrypted with cipher. timeout bounds each POST.
+func NewSender(secret string, repository *repo.Repository, cipher *auth.SecretCipher, timeout time.Duration) *Sender {
+	return &Sender{
+		secret: []byte(secret),
+		repo:   repository,
+		cipher: cipher,
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect could send the signed payload somewhere unexpected
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// signingKey returns the key callbacks for keyID's notifications are signed
+// with; an empty keyID or the shared client uses the shared secret
+func (s *Sender) signingKey(ctx context.Context, keyID string) ([]byte, error) {
+	if keyID == "" || keyID == auth.SharedKeyID {
+		return s.secret, nil
+	}
+	row, err := s.repo.GetAPIClientCallbackSecret(ctx, keyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrKeyUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client secret: %w", err)
+	}
+	if row.Status != "active" {
+		return nil, ErrKeyUnavailable
+	}
+	if s.cipher == nil {
+		return nil, fmt.Errorf("api client secret key not configured")
+	}
+	return s.cipher.Decrypt(row.SecretCiphertext)
+}
+
+// Send POSTs a callback body, signed for keyID, the API client that created
+// the notification. Failures are reported in the result, classified like
+// webhook deliveries, so they can be retried the same way.
+func (s *Sender) Send(ctx context.Context, callbackURL string, notificationID uuid.UUID, event, keyID string, body []byte) (*channel.DeliveryResult, error) {
+	startTime := time.Now()
+
+	key, err := s.signingKey(ctx, keyID)
+	if errors.Is(err, ErrKeyUnavailable) {
+		return &channel.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Sprintf("no callback signing key for api client %s", keyID),
+			Permanent: true,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse(callbackURL)
+	if err != nil {
+		return &channel.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Sprintf("invalid callback url: %v", err),
+			Permanent: true,
+		}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notification-service-callback")
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Signature", auth.Sign(key, http.MethodPost, target.Path, body, timestamp))
+	if keyID != "" && keyID != auth.SharedKeyID {
+		req.Header.Set("X-Key-ID", keyID)
+	}
+	req.Header.Set("X-Notification-ID", notificationID.String())
+	req.Header.Set("X-Callback-Event", event)
+
+	resp, err := s.client.Do(req)
+
+	result := &channel.DeliveryResult{
+		LatencyMs: int(time.Since(startTime).Milliseconds()),
+	}
+
+	if err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		return result, nil
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	result.HTTPStatus = resp.StatusCode
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		result.Success = true
+
+	case resp.StatusCode == http.StatusTooManyRequests: // 429
+		result.Success = false
+		result.Error = "rate limited (429)"
+		result.RetryAfter = channel.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		// Redirects aren't followed, and won't go away on retry
+		result.Success = false
+		result.Error = fmt.Sprintf("unexpected redirect: %d", resp.StatusCode)
+		result.Permanent = true
+
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// Classified by status: 400/401/403/413 are permanent
+		result.Success = false
+		result.Error = fmt.Sprintf("client error: %d", resp.StatusCode)
+
+	default:
+		// Server error - temporary failure, should retry
+		result.Success = false
+		result.Error = fmt.Sprintf("server error: %d", resp.StatusCode)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,3 @@
+// Package callback reports notification and delivery attempt status back to
+// producers as signed HTTPS POSTs
+package callback
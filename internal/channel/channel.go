@@ -0,0 +1,71 @@
+package channel
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channel names, as stored on notifications and delivery attempts
+const (
+	Push    = "push"
+	Email   = "email"
+	Webhook = "webhook"
+)
+
+// Valid reports whether name is a known channel
+func Valid(name string) bool {
+	return name == Push || name == Email || name == Webhook
+}
+
+// DeliveryResult contains the outcome of a delivery attempt on any channel
+type DeliveryResult struct {
+	Success     bool
+	HTTPStatus  int
+	LatencyMs   int
+	Error       string
+	ShouldPrune bool          // True if the target should be deactivated (410 Gone)
+	Skipped     bool          // True if delivery was not attempted (inactive target)
+	Permanent   bool          // True if retrying cannot succeed (e.g. mailbox rejected)
+	RetryAfter  time.Duration // Delay requested by the provider (429 Retry-After)
+
+	// Template version the copy was rendered from, when one was used
+	TemplateID      *uuid.UUID
+	TemplateVersion *int32
+}
+
+// Sender delivers a notification to a single target: a device subscription
+// for push, or a contact point for other channels
+type Sender interface {
+	SendNotification(ctx context.Context, notificationID, targetID uuid.UUID, userID string) (*DeliveryResult, error)
+}
+
+// ParseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// FirstNonEmpty returns the explicit value if set, otherwise the fallback.
+// Senders use it to let a notification's own copy override its template.
+func FirstNonEmpty(explicit *string, fallback string) string {
+	if explicit != nil && *explicit != "" {
+		return *explicit
+	}
+	return fallback
+}
@@ -0,0 +1,2 @@
+// Package channel defines the delivery channels notifications are sent on
+package channel
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -15,6 +18,69 @@ type Config struct {
 	HMACSecret         string   `envconfig:"HMAC_SECRET" required:"true"`
 	LogLevel           string   `envconfig:"LOG_LEVEL" default:"info"`
 	CORSAllowedOrigins []string `envconfig:"CORS_ALLOWED_ORIGINS"`
+	DefaultLocale      string   `envconfig:"DEFAULT_LOCALE" default:"en"`
+
+	// How long an idempotency_key is held before it can be reused
+	IdempotencyRetention time.Duration `envconfig:"IDEMPOTENCY_RETENTION" default:"24h"`
+
+	// Outbox relay (worker)
+	OutboxPollInterval time.Duration `envconfig:"OUTBOX_POLL_INTERVAL" default:"1s"`
+	OutboxBatchSize    int           `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxRetention    time.Duration `envconfig:"OUTBOX_RETENTION" default:"24h"`
+
+	// Delivery retry policy overrides per priority, e.g. "high:5,normal:3"
+	RetryMaxRetry  map[string]int           `envconfig:"RETRY_MAX_RETRY"`
+	RetryBaseDelay map[string]time.Duration `envconfig:"RETRY_BASE_DELAY"`
+	RetryMaxDelay  map[string]time.Duration `envconfig:"RETRY_MAX_DELAY"`
+
+	// Dedupe suppression window, with overrides per notification type
+	DedupeWindow  time.Duration            `envconfig:"DEDUPE_WINDOW" default:"10m"`
+	DedupeWindows map[string]time.Duration `envconfig:"DEDUPE_WINDOWS"`
+
+	// IANA timezone for deliver_local_time and quiet hours when none is set
+	DefaultTimezone string `envconfig:"DEFAULT_TIMEZONE" default:"UTC"`
+
+	// SMTP relay for the email channel (disabled when SMTPHost is empty)
+	SMTPHost     string `envconfig:"SMTP_HOST"`
+	SMTPPort     int    `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD"`
+	SMTPFrom     string `envconfig:"SMTP_FROM" default:"Notifications <notifications@localhost>"`
+
+	// Per-request timeout for webhook deliveries (must stay under the 30s task timeout)
+	WebhookTimeout time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+
+	// Public base URL of the API for tracked push links and client events
+	// (tracking is disabled when empty), and the secret their tokens are signed with
+	TrackingBaseURL string `envconfig:"TRACKING_BASE_URL"`
+	TrackingSecret  string `envconfig:"TRACKING_SECRET"`
+
+	// Public base URL of the web app that relative notification URLs (e.g. the
+	// built-in /dashboards/... links) resolve against for tracked clicks
+	AppBaseURL string `envconfig:"APP_BASE_URL"`
+
+	// Secret that inbox stream tokens are signed with (streams are disabled
+	// when empty), and how long a minted token can open a stream
+	StreamTokenSecret string        `envconfig:"STREAM_TOKEN_SECRET"`
+	StreamTokenTTL    time.Duration `envconfig:"STREAM_TOKEN_TTL" default:"5m"`
+
+	// Status callbacks to producers: the callback_url used when neither a
+	// notification nor its API client has one (API), and the timeout for each
+	// POST (worker). Callbacks are signed with the creating API client's newest
+	// secret, or HMACSecret for notifications sent with it.
+	DefaultCallbackURL string        `envconfig:"DEFAULT_CALLBACK_URL"`
+	CallbackTimeout    time.Duration `envconfig:"CALLBACK_TIMEOUT" default:"10s"`
+
+	// Base64 32-byte key that API client secrets are encrypted with at rest.
+	// Secrets are encrypted rather than hashed because HMAC signing and
+	// verification need the raw secret. API clients can't be created or
+	// authenticated, nor their callbacks signed, when empty, so it is required
+	// when SharedSecretAuth is false.
+	APIClientSecretKey string `envconfig:"API_CLIENT_SECRET_KEY"`
+
+	// Accept requests without X-Key-ID signed with HMACSecret, as a client
+	// with every scope. Disable once every producer has its own API client.
+	SharedSecretAuth bool `envconfig:"SHARED_SECRET_AUTH" default:"true"`
 }
 
 // Load reads config from environment variables with validation.
@@ -24,5 +90,29 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("", &cfg); err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	if cfg.TrackingBaseURL != "" && cfg.TrackingSecret == "" {
+		return nil, fmt.Errorf("TRACKING_SECRET is required when TRACKING_BASE_URL is set")
+	}
+	if cfg.AppBaseURL != "" {
+		if u, err := url.Parse(cfg.AppBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("APP_BASE_URL must be an absolute http(s) URL")
+		}
+	}
+	if !cfg.SharedSecretAuth && cfg.APIClientSecretKey == "" {
+		return nil, fmt.Errorf("API_CLIENT_SECRET_KEY is required when SHARED_SECRET_AUTH is false")
+	}
+	if cfg.APIClientSecretKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(cfg.APIClientSecretKey); err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("API_CLIENT_SECRET_KEY must be a base64-encoded 32-byte key")
+		}
+	}
+	if cfg.StreamTokenTTL <= 0 {
+		return nil, fmt.Errorf("STREAM_TOKEN_TTL must be positive")
+	}
+	if cfg.DefaultCallbackURL != "" {
+		if u, err := url.Parse(cfg.DefaultCallbackURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("DEFAULT_CALLBACK_URL must be an absolute https URL")
+		}
+	}
 	return &cfg, nil
 }
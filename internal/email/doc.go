@@ -0,0 +1,2 @@
+// Package email delivers notifications over SMTP
+package email
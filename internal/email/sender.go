@@ -0,0 +1,303 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"notifications/internal/channel"
+	"notifications/internal/repo"
+	"notifications/internal/templates"
+)
+
+// Config holds the SMTP relay settings
+type Config struct {
+	Host     string
+	Port     int
+	Username string // Optional; AUTH PLAIN is used when set
+	Password string
+	From     string // e.g. "Notifications <notifications@example.com>"
+}
+
+// Sender handles sending notifications by email
+type Sender struct {
+	cfg      Config
+	from     *mail.Address
+	repo     *repo.Repository
+	resolver *templates.Resolver
+}
+
+// NewSender creates a new email sender
+func NewSender(cfg Config, repository *repo.Repository, resolver *templates.Resolver) (*Sender, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	return &Sender{
+		cfg:      cfg,
+		from:     from,
+		repo:     repository,
+		resolver: resolver,
+	}, nil
+}
+
+// SendNotification emails a notification to a contact point
+func (s *Sender) SendNotification(
+	ctx context.Context,
+	notificationID uuid.UUID,
+	contactPointID uuid.UUID,
+	userID string,
+) (*channel.DeliveryResult, error) {
+	startTime := time.Now()
+
+	contact, err := s.repo.GetContactPoint(ctx, contactPointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact point: %w", err)
+	}
+	if !contact.IsActive {
+		return &channel.DeliveryResult{
+			Success: false,
+			Error:   "contact point is not active",
+			Skipped: true,
+		}, nil
+	}
+
+	notif, err := s.repo.GetNotification(ctx, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	// Missing template variables render as empty strings and don't block delivery
+	msg, err := BuildMessage(ctx, s.resolver, notif, contact.Locale)
+	var missing *templates.MissingVariablesError
+	if err != nil && !errors.As(err, &missing) {
+		return &channel.DeliveryResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to build message: %v", err),
+		}, nil
+	}
+
+	to := &mail.Address{Address: contact.Address}
+	data, err := msg.encode(s.from, to, fmt.Sprintf("<%s.%s@%s>", notif.ID, contact.ID, s.cfg.Host))
+	if err != nil {
+		return &channel.DeliveryResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to encode message: %v", err),
+		}, nil
+	}
+
+	err = s.send(ctx, to.Address, data)
+	result := &channel.DeliveryResult{
+		LatencyMs:       int(time.Since(startTime).Milliseconds()),
+		TemplateID:      msg.TemplateID,
+		TemplateVersion: msg.TemplateVersion,
+	}
+	if err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		// 5xx replies (unknown mailbox, rejected content) won't succeed on retry
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			result.Permanent = true
+		}
+		return result, nil
+	}
+
+	result.Success = true
+	return result, nil
+}
+
+// send delivers one message over SMTP, upgrading to TLS when the server
+// offers STARTTLS. The context deadline bounds the whole exchange.
+func (s *Sender) send(ctx context.Context, to string, data []byte) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("rcpt to: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	return client.Quit()
+}
+
+// Message is a rendered email with plain text and HTML alternatives
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+
+	// Template version the copy was rendered from, when one was used
+	TemplateID      *uuid.UUID
+	TemplateVersion *int32
+}
+
+// htmlTemplate lays out the rendered notification copy as an HTML email
+var htmlTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<body style="font-family: sans-serif; line-height: 1.5;">
+{{- if .Title}}
+<h2>{{.Title}}</h2>
+{{- end}}
+{{- if .Body}}
+<p>{{.Body}}</p>
+{{- end}}
+{{- if .URL}}
+<p><a href="{{.URL}}">{{.URL}}</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// BuildMessage renders the email for a notification as it would be delivered
+// to a contact point with the given locale. Copy comes from the same templates
+// and explicit overrides as the push payload (see webpush.BuildPayload).
+// A *templates.MissingVariablesError is returned alongside a usable message
+// when the data lacks template variables.
+func BuildMessage(ctx context.Context, resolver *templates.Resolver, notif repo.Notification, contactLocale *string) (*Message, error) {
+	var data map[string]interface{}
+	if len(notif.Data) > 0 {
+		_ = json.Unmarshal(notif.Data, &data)
+	}
+
+	content, renderErr := resolver.ResolveNotification(ctx, notif, data, notif.Locale, contactLocale)
+	var missing *templates.MissingVariablesError
+	if renderErr != nil && !errors.As(renderErr, &missing) {
+		if !errors.Is(renderErr, templates.ErrTemplateNotFound) {
+			return nil, renderErr
+		}
+		renderErr = nil
+	}
+	if content == nil {
+		content = &templates.Content{Locale: resolver.ChooseLocale(notif.Locale, contactLocale)}
+	}
+
+	view := struct {
+		Locale, Title, Body, URL string
+	}{
+		Locale: content.Locale,
+		Title:  channel.FirstNonEmpty(notif.Title, content.Title),
+		Body:   channel.FirstNonEmpty(notif.Body, content.Body),
+		URL:    channel.FirstNonEmpty(notif.Url, content.URL),
+	}
+
+	msg := &Message{
+		Subject:         view.Title,
+		TemplateID:      content.TemplateID,
+		TemplateVersion: content.TemplateVersion,
+	}
+	if msg.Subject == "" {
+		msg.Subject = notif.Type
+	}
+
+	var text bytes.Buffer
+	for _, part := range []string{view.Title, view.Body, view.URL} {
+		if part == "" {
+			continue
+		}
+		if text.Len() > 0 {
+			text.WriteString("\r\n\r\n")
+		}
+		text.WriteString(part)
+	}
+	msg.Text = text.String()
+
+	var html bytes.Buffer
+	if err := htmlTemplate.Execute(&html, view); err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+	msg.HTML = html.String()
+
+	return msg, renderErr
+}
+
+// encode formats the message as a multipart/alternative MIME message
+func (m *Message) encode(from, to *mail.Address, messageID string) ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", to)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "Message-ID: %s\r\n", messageID)
+	out.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", body.Boundary())
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	out.Write(buf.Bytes())
+	return out.Bytes(), nil
+}
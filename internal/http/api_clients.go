@@ -0,0 +1,383 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/auth"
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// API client statuses
+const (
+	apiClientActive   = "active"
+	apiClientDisabled = "disabled"
+)
+
+// errSecretsNotConfigured is returned for keyed requests when no
+// API_CLIENT_SECRET_KEY is set, since client secrets can't be decrypted
+var errSecretsNotConfigured = errors.New("api client secret key not configured")
+
+// apiClientStore looks up API clients for auth.VerifyClientMiddleware,
+// decrypting their secrets with cipher
+type apiClientStore struct {
+	repo   *repo.Repository
+	cipher *auth.SecretCipher
+}
+
+// LookupClient implements auth.ClientStore
+func (s apiClientStore) LookupClient(ctx context.Context, keyID string) (auth.Client, bool, [][]byte, error) {
+	if s.cipher == nil {
+		return auth.Client{}, false, nil, errSecretsNotConfigured
+	}
+	client, err := s.repo.GetAPIClientByKeyID(ctx, keyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return auth.Client{}, false, nil, auth.ErrClientNotFound
+	}
+	if err != nil {
+		return auth.Client{}, false, nil, err
+	}
+	secrets, err := s.repo.ListAPIClientSecrets(ctx, client.ID)
+	if err != nil {
+		return auth.Client{}, false, nil, err
+	}
+	keys := make([][]byte, 0, len(secrets))
+	for _, secret := range secrets {
+		key, err := s.cipher.Decrypt(secret.SecretCiphertext)
+		if err != nil {
+			return auth.Client{}, false, nil, fmt.Errorf("failed to decrypt secret %s: %w", secret.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	var callbackURL string
+	if client.CallbackUrl != nil {
+		callbackURL = *client.CallbackUrl
+	}
+	return auth.Client{
+		KeyID:       client.KeyID,
+		Name:        client.Name,
+		Scopes:      client.Scopes,
+		CallbackURL: callbackURL,
+	}, client.Status == apiClientActive, keys, nil
+}
+
+// CreateAPIClient handles POST /v1/admin/api-clients. The client's key ID and
+// first secret are generated; the secret is returned only in this response.
+func (h *Handler) CreateAPIClient(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var req CreateAPIClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 400)
+		return
+	}
+
+	if h.secretCipher == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "api client secrets not configured", "NOT_CONFIGURED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 503)
+		return
+	}
+
+	keyID, err := auth.NewKeyID()
+	if err != nil {
+		h.logger.Error("failed to generate key ID", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 500)
+		return
+	}
+	secret, err := auth.NewSecret()
+	if err != nil {
+		h.logger.Error("failed to generate client secret", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 500)
+		return
+	}
+	ciphertext, err := h.secretCipher.Encrypt(secret)
+	if err != nil {
+		h.logger.Error("failed to encrypt client secret", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 500)
+		return
+	}
+
+	var client repo.ApiClient
+	var clientSecret repo.ApiClientSecret
+	err = h.repo.WithTx(r.Context(), func(q *repo.Queries) error {
+		var err error
+		client, err = q.CreateAPIClient(r.Context(), repo.CreateAPIClientParams{
+			KeyID:       keyID,
+			Name:        req.Name,
+			Scopes:      req.Scopes,
+			CallbackUrl: req.CallbackURL,
+		})
+		if err != nil {
+			return err
+		}
+		clientSecret, err = q.CreateAPIClientSecret(r.Context(), repo.CreateAPIClientSecretParams{
+			ClientID:         client.ID,
+			SecretCiphertext: ciphertext,
+		})
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to save api client", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to save api client", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 500)
+		return
+	}
+
+	h.logger.Info("api client created", zap.String("key_id", client.KeyID), zap.Strings("scopes", client.Scopes))
+
+	resp := toAPIClientResponse(client)
+	secretResp := toAPIClientSecretResponse(clientSecret)
+	secretResp.Secret = secret
+	resp.Secrets = []APIClientSecretResponse{secretResp}
+	h.respondJSON(w, http.StatusCreated, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/admin/api-clients", 201, time.Since(start).Seconds())
+}
+
+// ListAPIClients handles GET /v1/admin/api-clients
+func (h *Handler) ListAPIClients(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	clients, err := h.repo.ListAPIClients(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list api clients", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/admin/api-clients", 500)
+		return
+	}
+
+	resp := ListAPIClientsResponse{
+		Clients: make([]APIClientResponse, len(clients)),
+	}
+	for i, client := range clients {
+		resp.Clients[i] = toAPIClientResponse(client)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/admin/api-clients", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/admin/api-clients", 200, time.Since(start).Seconds())
+}
+
+// GetAPIClient handles GET /v1/admin/api-clients/:key_id. Secrets are listed
+// by ID only.
+func (h *Handler) GetAPIClient(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	client, ok := h.loadAPIClient(w, r, "GET", "/v1/admin/api-clients/:key_id")
+	if !ok {
+		return
+	}
+
+	secrets, err := h.repo.ListAPIClientSecrets(r.Context(), client.ID)
+	if err != nil {
+		h.logger.Error("failed to list api client secrets", zap.Error(err), zap.String("key_id", client.KeyID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/admin/api-clients/:key_id", 500)
+		return
+	}
+
+	resp := toAPIClientResponse(client)
+	resp.Secrets = make([]APIClientSecretResponse, len(secrets))
+	for i, secret := range secrets {
+		resp.Secrets[i] = toAPIClientSecretResponse(secret)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/admin/api-clients/:key_id", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/admin/api-clients/:key_id", 200, time.Since(start).Seconds())
+}
+
+// UpdateAPIClient handles PUT /v1/admin/api-clients/:key_id. Disabling a
+// client rejects its requests immediately; its secrets are kept. A new
+// callback_url applies to notifications sent after the change.
+func (h *Handler) UpdateAPIClient(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	keyID := chi.URLParam(r, "key_id")
+
+	var req UpdateAPIClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/admin/api-clients/:key_id", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/admin/api-clients/:key_id", 400)
+		return
+	}
+
+	client, err := h.repo.UpdateAPIClient(r.Context(), repo.UpdateAPIClientParams{
+		Name:        req.Name,
+		Scopes:      req.Scopes,
+		Status:      req.Status,
+		CallbackUrl: req.CallbackURL,
+		KeyID:       keyID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.respondError(w, http.StatusNotFound, "api client not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("PUT", "/v1/admin/api-clients/:key_id", 404)
+			return
+		}
+		h.logger.Error("failed to update api client", zap.Error(err), zap.String("key_id", keyID))
+		h.respondError(w, http.StatusInternalServerError, "failed to update api client", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/admin/api-clients/:key_id", 500)
+		return
+	}
+
+	h.logger.Info("api client updated", zap.String("key_id", client.KeyID), zap.String("status", client.Status))
+
+	h.respondJSON(w, http.StatusOK, toAPIClientResponse(client))
+	metrics.IncHTTPRequestsTotal("PUT", "/v1/admin/api-clients/:key_id", 200)
+	metrics.ObserveRequestDuration("PUT", "/v1/admin/api-clients/:key_id", 200, time.Since(start).Seconds())
+}
+
+// CreateAPIClientSecret handles POST /v1/admin/api-clients/:key_id/secrets.
+// The client's existing secrets stay valid until deleted, so producers can
+// rotate without downtime. The secret is returned only in this response.
+func (h *Handler) CreateAPIClientSecret(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	if h.secretCipher == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "api client secrets not configured", "NOT_CONFIGURED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients/:key_id/secrets", 503)
+		return
+	}
+
+	client, ok := h.loadAPIClient(w, r, "POST", "/v1/admin/api-clients/:key_id/secrets")
+	if !ok {
+		return
+	}
+
+	secret, err := auth.NewSecret()
+	if err != nil {
+		h.logger.Error("failed to generate client secret", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients/:key_id/secrets", 500)
+		return
+	}
+	ciphertext, err := h.secretCipher.Encrypt(secret)
+	if err != nil {
+		h.logger.Error("failed to encrypt client secret", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients/:key_id/secrets", 500)
+		return
+	}
+
+	clientSecret, err := h.repo.CreateAPIClientSecret(r.Context(), repo.CreateAPIClientSecretParams{
+		ClientID:         client.ID,
+		SecretCiphertext: ciphertext,
+	})
+	if err != nil {
+		h.logger.Error("failed to save api client secret", zap.Error(err), zap.String("key_id", client.KeyID))
+		h.respondError(w, http.StatusInternalServerError, "failed to save api client secret", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients/:key_id/secrets", 500)
+		return
+	}
+
+	h.logger.Info("api client secret created", zap.String("key_id", client.KeyID), zap.String("secret_id", clientSecret.ID.String()))
+
+	resp := toAPIClientSecretResponse(clientSecret)
+	resp.Secret = secret
+	h.respondJSON(w, http.StatusCreated, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/admin/api-clients/:key_id/secrets", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/admin/api-clients/:key_id/secrets", 201, time.Since(start).Seconds())
+}
+
+// DeleteAPIClientSecret handles DELETE /v1/admin/api-clients/:key_id/secrets/:id.
+// Requests signed with the secret are rejected from then on.
+func (h *Handler) DeleteAPIClientSecret(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid secret ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/api-clients/:key_id/secrets/:id", 400)
+		return
+	}
+
+	client, ok := h.loadAPIClient(w, r, "DELETE", "/v1/admin/api-clients/:key_id/secrets/:id")
+	if !ok {
+		return
+	}
+
+	deleted, err := h.repo.DeleteAPIClientSecret(r.Context(), repo.DeleteAPIClientSecretParams{ID: id, ClientID: client.ID})
+	if err != nil {
+		h.logger.Error("failed to delete api client secret", zap.Error(err), zap.String("secret_id", id.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete api client secret", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/api-clients/:key_id/secrets/:id", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "api client secret not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/api-clients/:key_id/secrets/:id", 404)
+		return
+	}
+
+	h.logger.Info("api client secret deleted", zap.String("key_id", client.KeyID), zap.String("secret_id", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/api-clients/:key_id/secrets/:id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/admin/api-clients/:key_id/secrets/:id", 204, time.Since(start).Seconds())
+}
+
+// loadAPIClient fetches the client named in the URL, responding with 404 or 500 on failure.
+func (h *Handler) loadAPIClient(w http.ResponseWriter, r *http.Request, method, path string) (repo.ApiClient, bool) {
+	keyID := chi.URLParam(r, "key_id")
+
+	client, err := h.repo.GetAPIClientByKeyID(r.Context(), keyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.respondError(w, http.StatusNotFound, "api client not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal(method, path, 404)
+			return repo.ApiClient{}, false
+		}
+		h.logger.Error("failed to get api client", zap.Error(err), zap.String("key_id", keyID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal(method, path, 500)
+		return repo.ApiClient{}, false
+	}
+	return client, true
+}
+
+// toAPIClientResponse converts an API client row to its API representation,
+// without its secrets.
+func toAPIClientResponse(c repo.ApiClient) APIClientResponse {
+	return APIClientResponse{
+		KeyID:       c.KeyID,
+		Name:        c.Name,
+		Scopes:      c.Scopes,
+		Status:      c.Status,
+		CallbackURL: c.CallbackUrl,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// toAPIClientSecretResponse converts a secret row to its API representation,
+// without the secret itself.
+func toAPIClientSecretResponse(s repo.ApiClientSecret) APIClientSecretResponse {
+	return APIClientSecretResponse{
+		ID:        s.ID,
+		CreatedAt: s.CreatedAt,
+	}
+}
@@ -0,0 +1,155 @@
+package apihttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"notifications/internal/channel"
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+	"notifications/internal/webhook"
+)
+
+// ListContactPoints handles GET /v1/users/:user_id/contact-points
+func (h *Handler) ListContactPoints(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	contacts, err := h.repo.ListContactPointsByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list contact points", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/contact-points", 500)
+		return
+	}
+
+	resp := ListContactPointsResponse{
+		UserID:        userID,
+		ContactPoints: make([]ContactPointResponse, len(contacts)),
+	}
+	for i, contact := range contacts {
+		resp.ContactPoints[i] = toContactPointResponse(contact)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/contact-points", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/users/:user_id/contact-points", 200, time.Since(start).Seconds())
+}
+
+// CreateContactPoint handles POST /v1/users/:user_id/contact-points.
+// Registering an existing address reactivates it and updates its locale.
+// Webhook contact points get a signing secret, returned only in this response.
+func (h *Handler) CreateContactPoint(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	if len(userID) > 255 {
+		h.respondError(w, http.StatusBadRequest, "user_id exceeds 255 characters", "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/contact-points", 400)
+		return
+	}
+
+	var req ContactPointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/contact-points", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/contact-points", 400)
+		return
+	}
+
+	var secret *string
+	if req.Channel == channel.Webhook {
+		s, err := webhook.NewSecret()
+		if err != nil {
+			h.logger.Error("failed to generate webhook secret", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/contact-points", 500)
+			return
+		}
+		secret = &s
+	}
+
+	contact, err := h.repo.CreateContactPoint(r.Context(), repo.CreateContactPointParams{
+		UserID:  userID,
+		Channel: req.Channel,
+		Address: req.Address,
+		Locale:  req.Locale,
+		Secret:  secret,
+	})
+	if err != nil {
+		h.logger.Error("failed to save contact point", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to save contact point", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/contact-points", 500)
+		return
+	}
+
+	h.logger.Info("contact point saved",
+		zap.String("contact_point_id", contact.ID.String()),
+		zap.String("user_id", userID),
+		zap.String("channel", contact.Channel),
+	)
+
+	resp := toContactPointResponse(contact)
+	resp.Secret = contact.Secret
+	h.respondJSON(w, http.StatusCreated, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/contact-points", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/users/:user_id/contact-points", 201, time.Since(start).Seconds())
+}
+
+// DeleteContactPoint handles DELETE /v1/users/:user_id/contact-points/:id
+func (h *Handler) DeleteContactPoint(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid contact point ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/contact-points/:id", 400)
+		return
+	}
+
+	deleted, err := h.repo.DeleteContactPoint(r.Context(), repo.DeleteContactPointParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		h.logger.Error("failed to delete contact point", zap.Error(err), zap.String("contact_point_id", id.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete contact point", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/contact-points/:id", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "contact point not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/contact-points/:id", 404)
+		return
+	}
+
+	h.logger.Info("contact point deleted", zap.String("contact_point_id", id.String()), zap.String("user_id", userID))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/contact-points/:id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/users/:user_id/contact-points/:id", 204, time.Since(start).Seconds())
+}
+
+// toContactPointResponse converts a contact point row to its API representation.
+func toContactPointResponse(contact repo.UserContactPoint) ContactPointResponse {
+	return ContactPointResponse{
+		ID:        contact.ID,
+		UserID:    contact.UserID,
+		Channel:   contact.Channel,
+		Address:   contact.Address,
+		Locale:    contact.Locale,
+		IsActive:  contact.IsActive,
+		CreatedAt: contact.CreatedAt,
+		UpdatedAt: contact.UpdatedAt,
+	}
+}
@@ -0,0 +1,369 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/queue"
+	"notifications/internal/repo"
+)
+
+// maxBulkDeadLetters caps how many dead letters a single bulk request touches
+const maxBulkDeadLetters = 1000
+
+// ListDeadLetters handles GET /v1/admin/dlq
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/admin/dlq", 400)
+		return
+	}
+
+	letters, err := h.inspector.ListDeadLetters(r.URL.Query().Get("queue"), int(limit), int(offset))
+	if err != nil {
+		h.logger.Error("failed to list dead letters", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/admin/dlq", 500)
+		return
+	}
+
+	total, err := h.inspector.CountDeadLetters(r.URL.Query().Get("queue"))
+	if err != nil {
+		h.logger.Error("failed to count dead letters", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/admin/dlq", 500)
+		return
+	}
+
+	resp := ListDeadLettersResponse{
+		DeadLetters: make([]DeadLetterResponse, len(letters)),
+		Total:       total,
+	}
+	for i, letter := range letters {
+		resp.DeadLetters[i] = toDeadLetterResponse(letter)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/admin/dlq", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/admin/dlq", 200, time.Since(start).Seconds())
+}
+
+// GetDeadLetter handles GET /v1/admin/dlq/:queue/:task_id
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	letter, ok := h.loadDeadLetter(w, r, "GET", "/v1/admin/dlq/:queue/:task_id")
+	if !ok {
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, toDeadLetterResponse(letter))
+	metrics.IncHTTPRequestsTotal("GET", "/v1/admin/dlq/:queue/:task_id", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/admin/dlq/:queue/:task_id", 200, time.Since(start).Seconds())
+}
+
+// RequeueDeadLetter handles POST /v1/admin/dlq/:queue/:task_id/requeue
+func (h *Handler) RequeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	letter, ok := h.loadDeadLetter(w, r, "POST", "/v1/admin/dlq/:queue/:task_id/requeue")
+	if !ok {
+		return
+	}
+
+	if err := h.requeueDeadLetter(ctx, letter); err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			h.respondError(w, http.StatusNotFound, "dead letter not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/:queue/:task_id/requeue", 404)
+			return
+		}
+		h.logger.Error("failed to requeue dead letter", zap.Error(err), zap.String("task_id", letter.ID))
+		h.respondError(w, http.StatusInternalServerError, "failed to requeue dead letter", "REQUEUE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/:queue/:task_id/requeue", 500)
+		return
+	}
+
+	h.logger.Info("dead letter requeued",
+		zap.String("task_id", letter.ID),
+		zap.String("queue", letter.Queue),
+	)
+
+	h.respondJSON(w, http.StatusOK, toDeadLetterResponse(letter))
+	metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/:queue/:task_id/requeue", 200)
+	metrics.ObserveRequestDuration("POST", "/v1/admin/dlq/:queue/:task_id/requeue", 200, time.Since(start).Seconds())
+}
+
+// PurgeDeadLetter handles DELETE /v1/admin/dlq/:queue/:task_id
+func (h *Handler) PurgeDeadLetter(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	queueName := chi.URLParam(r, "queue")
+	taskID := chi.URLParam(r, "task_id")
+
+	if err := h.inspector.Purge(queueName, taskID); err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			h.respondError(w, http.StatusNotFound, "dead letter not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/dlq/:queue/:task_id", 404)
+			return
+		}
+		h.logger.Error("failed to purge dead letter", zap.Error(err), zap.String("task_id", taskID))
+		h.respondError(w, http.StatusInternalServerError, "failed to purge dead letter", "PURGE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/dlq/:queue/:task_id", 500)
+		return
+	}
+
+	h.logger.Info("dead letter purged", zap.String("task_id", taskID), zap.String("queue", queueName))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/admin/dlq/:queue/:task_id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/admin/dlq/:queue/:task_id", 204, time.Since(start).Seconds())
+}
+
+// RequeueDeadLetters handles POST /v1/admin/dlq/requeue. Without task_ids,
+// every dead letter in the queue (or all queues) is requeued.
+func (h *Handler) RequeueDeadLetters(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	req, ok := h.decodeDeadLetterBulkRequest(w, r, "/v1/admin/dlq/requeue")
+	if !ok {
+		return
+	}
+
+	letters, err := h.collectDeadLetters(req)
+	if err != nil {
+		h.logger.Error("failed to list dead letters", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/requeue", 500)
+		return
+	}
+
+	resp := DeadLetterBulkResponse{Failed: []DeadLetterFailure{}}
+	for _, letter := range letters {
+		if err := h.requeueDeadLetter(ctx, letter); err != nil {
+			h.logger.Warn("failed to requeue dead letter", zap.Error(err), zap.String("task_id", letter.ID))
+			resp.Failed = append(resp.Failed, DeadLetterFailure{TaskID: letter.ID, Error: err.Error()})
+			continue
+		}
+		resp.Processed++
+	}
+	resp.Failed = append(resp.Failed, h.missingDeadLetters(req, letters)...)
+
+	h.logger.Info("dead letters requeued",
+		zap.Int("requeued", resp.Processed),
+		zap.Int("failed", len(resp.Failed)),
+	)
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/requeue", 200)
+	metrics.ObserveRequestDuration("POST", "/v1/admin/dlq/requeue", 200, time.Since(start).Seconds())
+}
+
+// PurgeDeadLetters handles POST /v1/admin/dlq/purge. Without task_ids,
+// every dead letter in the queue (or all queues) is deleted.
+func (h *Handler) PurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	req, ok := h.decodeDeadLetterBulkRequest(w, r, "/v1/admin/dlq/purge")
+	if !ok {
+		return
+	}
+
+	resp := DeadLetterBulkResponse{Failed: []DeadLetterFailure{}}
+	if len(req.TaskIDs) == 0 {
+		n, err := h.inspector.PurgeAll(req.Queue)
+		resp.Processed = n
+		if err != nil {
+			h.logger.Error("failed to purge dead letters", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "failed to purge dead letters", "PURGE_FAILED", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/purge", 500)
+			return
+		}
+	} else {
+		for _, id := range req.TaskIDs {
+			if err := h.inspector.Purge(req.Queue, id); err != nil {
+				resp.Failed = append(resp.Failed, DeadLetterFailure{TaskID: id, Error: err.Error()})
+				continue
+			}
+			resp.Processed++
+		}
+	}
+
+	h.logger.Info("dead letters purged",
+		zap.Int("purged", resp.Processed),
+		zap.Int("failed", len(resp.Failed)),
+	)
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/admin/dlq/purge", 200)
+	metrics.ObserveRequestDuration("POST", "/v1/admin/dlq/purge", 200, time.Since(start).Seconds())
+}
+
+// requeueDeadLetter records the requeue in the notification's attempt history
+// and reopens its aggregate status, then moves the task back to pending.
+// The history is written first so the delivery's next outcome always follows it.
+func (h *Handler) requeueDeadLetter(ctx context.Context, letter queue.DeadLetter) error {
+	delivery := letter.Delivery
+	if delivery != nil {
+		if err := h.recordRequeueAttempt(ctx, letter, queue.AttemptStatusRequeued, "requeued from dead-letter queue"); err != nil {
+			return fmt.Errorf("failed to record requeue: %w", err)
+		}
+	}
+
+	newID, err := h.inspector.Requeue(letter.Queue, letter.ID)
+	if errors.Is(err, queue.ErrDeadLetterNotRemoved) {
+		// The delivery is queued again; the leftover dead letter is removed by
+		// the next requeue or a purge
+		h.logger.Warn("requeued dead letter not removed", zap.Error(err),
+			zap.String("task_id", letter.ID), zap.String("new_task_id", newID))
+		return nil
+	}
+	if err != nil {
+		if delivery != nil {
+			// Restore a terminal outcome so the notification can complete again
+			if recErr := h.recordRequeueAttempt(ctx, letter, queue.AttemptStatusFailed, "requeue failed: "+err.Error()); recErr != nil {
+				h.logger.Error("failed to record failed requeue", zap.Error(recErr), zap.String("task_id", letter.ID))
+			} else if recErr := h.repo.WithTx(ctx, func(q *repo.Queries) error {
+				_, err := queue.RollupNotificationStatus(ctx, q, delivery.NotificationID)
+				return err
+			}); recErr != nil {
+				h.logger.Error("failed to roll up notification status", zap.Error(recErr))
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// recordRequeueAttempt writes an attempt for a dead-lettered delivery and
+// reopens the notification when the attempt is not terminal.
+func (h *Handler) recordRequeueAttempt(ctx context.Context, letter queue.DeadLetter, status, note string) error {
+	delivery := letter.Delivery
+	retryCount := int32(letter.Retried)
+	return h.repo.WithTx(ctx, func(q *repo.Queries) error {
+		params := delivery.AttemptParams()
+		params.Status = status
+		params.Error = &note
+		params.RetryCount = &retryCount
+		if _, err := queue.CreateDeliveryAttempt(ctx, q, params); err != nil {
+			return err
+		}
+		if status == queue.AttemptStatusRequeued {
+			return q.ReopenNotification(ctx, delivery.NotificationID)
+		}
+		return nil
+	})
+}
+
+// loadDeadLetter fetches the dead letter named in the URL, writing an error
+// response and returning false if it can't be loaded.
+func (h *Handler) loadDeadLetter(w http.ResponseWriter, r *http.Request, method, path string) (queue.DeadLetter, bool) {
+	letter, err := h.inspector.GetDeadLetter(chi.URLParam(r, "queue"), chi.URLParam(r, "task_id"))
+	if err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			h.respondError(w, http.StatusNotFound, "dead letter not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal(method, path, 404)
+			return queue.DeadLetter{}, false
+		}
+		h.logger.Error("failed to get dead letter", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal(method, path, 500)
+		return queue.DeadLetter{}, false
+	}
+	return letter, true
+}
+
+// decodeDeadLetterBulkRequest decodes and validates a bulk request body.
+// An empty body selects every dead letter.
+func (h *Handler) decodeDeadLetterBulkRequest(w http.ResponseWriter, r *http.Request, path string) (DeadLetterBulkRequest, bool) {
+	var req DeadLetterBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", path, 400)
+		return req, false
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", path, 400)
+		return req, false
+	}
+	return req, true
+}
+
+// collectDeadLetters loads the dead letters selected by a bulk request
+func (h *Handler) collectDeadLetters(req DeadLetterBulkRequest) ([]queue.DeadLetter, error) {
+	if len(req.TaskIDs) == 0 {
+		return h.inspector.ListDeadLetters(req.Queue, maxBulkDeadLetters, 0)
+	}
+
+	letters := make([]queue.DeadLetter, 0, len(req.TaskIDs))
+	for _, id := range req.TaskIDs {
+		letter, err := h.inspector.GetDeadLetter(req.Queue, id)
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			continue // Reported by missingDeadLetters
+		}
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// missingDeadLetters reports requested task IDs that were not dead letters
+func (h *Handler) missingDeadLetters(req DeadLetterBulkRequest, found []queue.DeadLetter) []DeadLetterFailure {
+	seen := make(map[string]bool, len(found))
+	for _, letter := range found {
+		seen[letter.ID] = true
+	}
+	var missing []DeadLetterFailure
+	for _, id := range req.TaskIDs {
+		if !seen[id] {
+			missing = append(missing, DeadLetterFailure{TaskID: id, Error: queue.ErrDeadLetterNotFound.Error()})
+		}
+	}
+	return missing
+}
+
+// toDeadLetterResponse converts a dead letter to its API representation.
+func toDeadLetterResponse(letter queue.DeadLetter) DeadLetterResponse {
+	resp := DeadLetterResponse{
+		ID:           letter.ID,
+		Queue:        letter.Queue,
+		Type:         letter.Type,
+		Payload:      letter.Payload,
+		LastError:    letter.LastError,
+		LastFailedAt: letter.LastFailedAt,
+		Retried:      letter.Retried,
+		MaxRetry:     letter.MaxRetry,
+	}
+	if d := letter.Delivery; d != nil {
+		notificationID, subscriptionID, userID, deliveryChannel := d.NotificationID, d.SubscriptionID, d.UserID, d.DeliveryChannel()
+		resp.NotificationID = &notificationID
+		resp.UserID = &userID
+		resp.Channel = &deliveryChannel
+		switch {
+		case d.TopicWebhookID != nil:
+			resp.TopicWebhookID = d.TopicWebhookID
+		case d.ContactPointID != nil:
+			resp.ContactPointID = d.ContactPointID
+		default:
+			resp.SubscriptionID = &subscriptionID
+		}
+	}
+	return resp
+}
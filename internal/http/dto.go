@@ -1,12 +1,21 @@
 package apihttp
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/mail"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"notifications/internal/auth"
+	"notifications/internal/channel"
+	"notifications/internal/queue"
+	"notifications/internal/webhook"
 )
 
 // ErrorResponse represents API error responses.
@@ -63,9 +72,24 @@ func (r *RegisterSubscriptionRequest) Validate() error {
 	if r.Timezone != nil && len(*r.Timezone) > 50 {
 		return fmt.Errorf("timezone exceeds 50 characters")
 	}
+	if len(r.Metadata) > maxMetadataKeys {
+		return fmt.Errorf("metadata exceeds maximum of %d keys", maxMetadataKeys)
+	}
 	return nil
 }
 
+// MetadataAsJSON returns the metadata field as JSON bytes.
+func (r *RegisterSubscriptionRequest) MetadataAsJSON() (json.RawMessage, error) {
+	if r.Metadata == nil {
+		return json.RawMessage("{}"), nil
+	}
+	b, err := json.Marshal(r.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return b, nil
+}
+
 // RegisterSubscriptionResponse represents successful subscription registration.
 type RegisterSubscriptionResponse struct {
 	ID        uuid.UUID `json:"id"`
@@ -80,6 +104,9 @@ type SendNotificationRequest struct {
 	IdempotencyKey *string                `json:"idempotency_key,omitempty"`
 	Type           string                 `json:"type"`
 	UserIDs        []string               `json:"user_ids"`
+	Topics         []string               `json:"topics,omitempty"`
+	Segment        *queue.Segment         `json:"segment,omitempty"`
+	Channels       []string               `json:"channels,omitempty"` // Defaults to push
 	Title          *string                `json:"title,omitempty"`
 	Body           *string                `json:"body,omitempty"`
 	Icon           *string                `json:"icon,omitempty"`
@@ -89,6 +116,14 @@ type SendNotificationRequest struct {
 	DedupeKey      *string                `json:"dedupe_key,omitempty"`
 	TTLSeconds     *int                   `json:"ttl_seconds,omitempty"`
 	Priority       *string                `json:"priority,omitempty"`
+	SendAt         *time.Time             `json:"send_at,omitempty"`
+	// DeliverLocalTime ("HH:MM") holds each device's delivery until that time
+	// in the subscription's timezone
+	DeliverLocalTime *string `json:"deliver_local_time,omitempty"`
+	// CallbackURL receives a signed POST when the notification reaches a
+	// terminal status and, with CallbackAttempts, one per delivery attempt
+	CallbackURL      *string `json:"callback_url,omitempty"`
+	CallbackAttempts bool    `json:"callback_attempts,omitempty"`
 }
 
 // Validate checks SendNotificationRequest fields.
@@ -99,8 +134,8 @@ func (r *SendNotificationRequest) Validate() error {
 	if len(r.Type) > 50 {
 		return fmt.Errorf("type exceeds 50 characters")
 	}
-	if len(r.UserIDs) == 0 {
-		return fmt.Errorf("user_ids is required and must contain at least one user")
+	if len(r.UserIDs) == 0 && len(r.Topics) == 0 && r.Segment == nil {
+		return fmt.Errorf("user_ids, topics or segment must be provided")
 	}
 	if len(r.UserIDs) > 1000 {
 		return fmt.Errorf("user_ids exceeds maximum of 1000 recipients")
@@ -113,6 +148,27 @@ func (r *SendNotificationRequest) Validate() error {
 			return fmt.Errorf("user_ids[%d] exceeds 255 characters", i)
 		}
 	}
+	if len(r.Topics) > maxTopicsPerNotification {
+		return fmt.Errorf("topics exceeds maximum of %d topics", maxTopicsPerNotification)
+	}
+	for i, topic := range r.Topics {
+		if err := validateTopicName(topic); err != nil {
+			return fmt.Errorf("topics[%d]: %w", i, err)
+		}
+	}
+	for i, name := range r.Channels {
+		if !channel.Valid(name) {
+			return fmt.Errorf("channels[%d] must be one of: push, email, webhook", i)
+		}
+	}
+	if r.Segment != nil {
+		if len(r.Topics) > 0 {
+			return fmt.Errorf("segment cannot be combined with topics")
+		}
+		if err := validateSegment(r.Segment); err != nil {
+			return fmt.Errorf("segment: %w", err)
+		}
+	}
 	if r.Title != nil && len(*r.Title) > 255 {
 		return fmt.Errorf("title exceeds 255 characters")
 	}
@@ -143,6 +199,24 @@ func (r *SendNotificationRequest) Validate() error {
 			return fmt.Errorf("priority must be one of: low, normal, high, critical")
 		}
 	}
+	if r.DeliverLocalTime != nil {
+		if _, err := time.Parse("15:04", *r.DeliverLocalTime); err != nil {
+			return fmt.Errorf("deliver_local_time must be HH:MM (24-hour)")
+		}
+	}
+	if r.CallbackURL != nil {
+		return validateCallbackURL(*r.CallbackURL)
+	}
+	return nil
+}
+
+func validateCallbackURL(callbackURL string) error {
+	if len(callbackURL) > maxWebhookURLLength {
+		return fmt.Errorf("callback_url exceeds %d characters", maxWebhookURLLength)
+	}
+	if err := webhook.ValidateURL(callbackURL); err != nil {
+		return fmt.Errorf("callback_url: %w", err)
+	}
 	return nil
 }
 
@@ -158,42 +232,159 @@ func (r *SendNotificationRequest) DataAsJSON() (json.RawMessage, error) {
 	return b, nil
 }
 
+// RequestHash returns a canonical hash of the request payload, used to detect
+// an idempotency_key reused for a different notification. The key itself is
+// excluded and user_ids are compared as a set.
+func (r *SendNotificationRequest) RequestHash() (string, error) {
+	canonical := *r
+	canonical.IdempotencyKey = nil
+	canonical.UserIDs = slices.Compact(slices.Sorted(slices.Values(r.UserIDs)))
+	canonical.Topics = slices.Compact(slices.Sorted(slices.Values(r.Topics)))
+	canonical.Channels = slices.Compact(slices.Sorted(slices.Values(r.Channels)))
+
+	// Map keys are sorted by encoding/json, so equal payloads marshal identically
+	b, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // SendNotificationResponse represents successful notification creation.
 type SendNotificationResponse struct {
-	ID             uuid.UUID `json:"id"`
-	Type           string    `json:"type"`
-	Status         string    `json:"status"`
-	RecipientCount int       `json:"recipient_count"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             uuid.UUID  `json:"id"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	RecipientCount int        `json:"recipient_count"`
+	SendAt         *time.Time `json:"send_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 // GetNotificationResponse represents notification status details.
+// Status is queued (or scheduled until SendAt), sending, then sent, partial,
+// failed or suppressed (every delivery skipped) once CompletedAt is set.
+// Cancelled scheduled notifications are cancelled.
 type GetNotificationResponse struct {
-	ID             uuid.UUID              `json:"id"`
-	Type           string                 `json:"type"`
-	Title          *string                `json:"title,omitempty"`
-	Body           *string                `json:"body,omitempty"`
-	Icon           *string                `json:"icon,omitempty"`
-	URL            *string                `json:"url,omitempty"`
-	Locale         *string                `json:"locale,omitempty"`
-	Data           map[string]interface{} `json:"data,omitempty"`
-	Status         string                 `json:"status"`
-	RecipientCount int                    `json:"recipient_count"`
-	CreatedAt      time.Time              `json:"created_at"`
+	ID                 uuid.UUID              `json:"id"`
+	Type               string                 `json:"type"`
+	Title              *string                `json:"title,omitempty"`
+	Body               *string                `json:"body,omitempty"`
+	Icon               *string                `json:"icon,omitempty"`
+	URL                *string                `json:"url,omitempty"`
+	Locale             *string                `json:"locale,omitempty"`
+	Data               map[string]interface{} `json:"data,omitempty"`
+	Status             string                 `json:"status"`
+	RecipientCount     int                    `json:"recipient_count"`
+	Topics             []string               `json:"topics,omitempty"`
+	Segment            *queue.Segment         `json:"segment,omitempty"`
+	Channels           []string               `json:"channels"`
+	ExpectedDeliveries *int                   `json:"expected_deliveries,omitempty"`
+	Counts             DeliveryCounts         `json:"counts"`
+	Engagement         EngagementCounts       `json:"engagement"`
+	Fallbacks          []FallbackResponse     `json:"fallbacks,omitempty"`
+	Template           *TemplateResponse      `json:"template,omitempty"`
+	SendAt             *time.Time             `json:"send_at,omitempty"`
+	DeliverLocalTime   *string                `json:"deliver_local_time,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	CompletedAt        *time.Time             `json:"completed_at,omitempty"`
+	CallbackURL        *string                `json:"callback_url,omitempty"`
+	CallbackAttempts   bool                   `json:"callback_attempts,omitempty"`
+	CreatedBy          *string                `json:"created_by,omitempty"` // Key ID of the API client that sent it
+}
+
+// DeliveryCounts holds the latest delivery outcome per subscription, by status.
+// Pending counts expected deliveries with no terminal outcome yet, including retrying ones.
+type DeliveryCounts struct {
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+	Pruned    int `json:"pruned"`
+	Skipped   int `json:"skipped"`
+	Retrying  int `json:"retrying"`
+	Deferred  int `json:"deferred"`
+	Pending   int `json:"pending"`
+}
+
+// EngagementCounts holds how many recipients' clients reported each event
+// for the notification, e.g. to compare displays against deliveries.
+type EngagementCounts struct {
+	Delivered int `json:"delivered"`
+	Displayed int `json:"displayed"`
+	Clicked   int `json:"clicked"`
+	Closed    int `json:"closed"`
+}
+
+// ReportEventRequest reports a client-side event for a push notification.
+// Token is the payload's tracking_token.
+type ReportEventRequest struct {
+	Token string `json:"token"`
+	Event string `json:"event"`
+}
+
+// Validate checks ReportEventRequest fields.
+func (r *ReportEventRequest) Validate() error {
+	if r.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+	if _, ok := reportedEvents[r.Event]; !ok {
+		return fmt.Errorf("event must be one of: shown, clicked, closed")
+	}
+	return nil
+}
+
+// EngagementResponse reports engagement per notification type for
+// notifications created since Since.
+type EngagementResponse struct {
+	Since time.Time        `json:"since"`
+	Types []TypeEngagement `json:"types"`
+}
+
+// TypeEngagement holds a notification type's delivered recipients and the
+// events their clients reported. OpenRate is displays and ClickRate clicks
+// per delivered recipient.
+type TypeEngagement struct {
+	Type      string  `json:"type"`
+	Delivered int     `json:"delivered"`
+	Displayed int     `json:"displayed"`
+	Clicked   int     `json:"clicked"`
+	Closed    int     `json:"closed"`
+	OpenRate  float64 `json:"open_rate"`
+	ClickRate float64 `json:"click_rate"`
+}
+
+// FallbackResponse represents a recipient's channel fallback. It is pending
+// until every push delivery to the recipient has failed, then triggered;
+// attempts sent for it carry its ID as fallback_id.
+type FallbackResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      string     `json:"user_id"`
+	Channel     string     `json:"channel"`
+	Status      string     `json:"status"`
+	Deliveries  int        `json:"deliveries"`
+	ProcessAt   *time.Time `json:"process_at,omitempty"`
+	TriggeredAt *time.Time `json:"triggered_at,omitempty"`
 }
 
 // DeliveryAttemptResponse represents a single delivery attempt.
 type DeliveryAttemptResponse struct {
 	ID             uuid.UUID  `json:"id"`
 	NotificationID uuid.UUID  `json:"notification_id"`
+	Channel        string     `json:"channel"`
 	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty"`
+	ContactPointID *uuid.UUID `json:"contact_point_id,omitempty"`
+	FallbackID     *uuid.UUID `json:"fallback_id,omitempty"`
+	TopicWebhookID *uuid.UUID `json:"topic_webhook_id,omitempty"`
 	UserID         string     `json:"user_id"`
 	Status         string     `json:"status"`
 	HTTPStatus     *int       `json:"http_status,omitempty"`
 	LatencyMs      *int       `json:"latency_ms,omitempty"`
 	Error          *string    `json:"error,omitempty"`
 	RetryCount     *int       `json:"retry_count,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
+	Reason         *string    `json:"reason,omitempty"`
+	// Template version the delivered copy was rendered from, when one was used
+	TemplateID      *uuid.UUID `json:"template_id,omitempty"`
+	TemplateVersion *int       `json:"template_version,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
 }
 
 // ListDeliveryAttemptsResponse represents list of delivery attempts.
@@ -203,6 +394,505 @@ type ListDeliveryAttemptsResponse struct {
 	Total          int                       `json:"total"`
 }
 
+// CreateTemplateRequest represents a new template version.
+type CreateTemplateRequest struct {
+	Type     string  `json:"type"`
+	Locale   string  `json:"locale"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	URL      *string `json:"url,omitempty"`
+	Icon     *string `json:"icon,omitempty"`
+	IsActive bool    `json:"is_active"`
+}
+
+// Validate checks CreateTemplateRequest fields.
+func (r *CreateTemplateRequest) Validate() error {
+	if strings.TrimSpace(r.Type) == "" {
+		return fmt.Errorf("type is required")
+	}
+	if len(r.Type) > 50 {
+		return fmt.Errorf("type exceeds 50 characters")
+	}
+	if strings.TrimSpace(r.Locale) == "" {
+		return fmt.Errorf("locale is required")
+	}
+	if len(r.Locale) > 10 {
+		return fmt.Errorf("locale exceeds 10 characters")
+	}
+	return validateTemplatePatterns(r.Title, r.Body, r.URL, r.Icon)
+}
+
+// UpdateTemplateRequest represents changes to a template. Versions are
+// immutable, so an update creates a new version of the same type and locale.
+type UpdateTemplateRequest struct {
+	Title    *string `json:"title,omitempty"`
+	Body     *string `json:"body,omitempty"`
+	URL      *string `json:"url,omitempty"`
+	Icon     *string `json:"icon,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// validateTemplatePatterns checks template pattern fields.
+func validateTemplatePatterns(title, body string, url, icon *string) error {
+	if strings.TrimSpace(title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if len(title) > 255 {
+		return fmt.Errorf("title exceeds 255 characters")
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("body is required")
+	}
+	if len(body) > 1000 {
+		return fmt.Errorf("body exceeds 1000 characters")
+	}
+	if url != nil && len(*url) > 500 {
+		return fmt.Errorf("url exceeds 500 characters")
+	}
+	if icon != nil && len(*icon) > 500 {
+		return fmt.Errorf("icon URL exceeds 500 characters")
+	}
+	return nil
+}
+
+// TemplateResponse represents a template version.
+type TemplateResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	Locale    string    `json:"locale"`
+	Version   int       `json:"version"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	URL       *string   `json:"url,omitempty"`
+	Icon      *string   `json:"icon,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListTemplatesResponse represents a list of template versions.
+type ListTemplatesResponse struct {
+	Templates []TemplateResponse `json:"templates"`
+	Total     int                `json:"total"`
+}
+
+// PreviewTemplateRequest represents a dry-run render of a notification type.
+type PreviewTemplateRequest struct {
+	Type       string                 `json:"type"`
+	Locale     *string                `json:"locale,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	TemplateID *uuid.UUID             `json:"template_id,omitempty"`
+	Title      *string                `json:"title,omitempty"`
+	Body       *string                `json:"body,omitempty"`
+	Icon       *string                `json:"icon,omitempty"`
+	URL        *string                `json:"url,omitempty"`
+}
+
+// Validate checks PreviewTemplateRequest fields.
+func (r *PreviewTemplateRequest) Validate() error {
+	if strings.TrimSpace(r.Type) == "" {
+		return fmt.Errorf("type is required")
+	}
+	if len(r.Type) > 50 {
+		return fmt.Errorf("type exceeds 50 characters")
+	}
+	if r.Locale != nil && len(*r.Locale) > 10 {
+		return fmt.Errorf("locale exceeds 10 characters")
+	}
+	return nil
+}
+
+// PreviewTemplateResponse contains the exact Web Push payload the worker would send.
+type PreviewTemplateResponse struct {
+	Payload          json.RawMessage `json:"payload"`
+	PayloadSize      int             `json:"payload_size"`
+	MaxPayloadSize   int             `json:"max_payload_size"`
+	ExceedsLimit     bool            `json:"exceeds_limit"`
+	Locale           string          `json:"locale"`
+	TemplateID       *uuid.UUID      `json:"template_id,omitempty"`
+	TemplateVersion  *int            `json:"template_version,omitempty"`
+	MissingVariables []string        `json:"missing_variables,omitempty"`
+	Errors           []string        `json:"errors,omitempty"`
+}
+
+// QuietHoursRequest sets a user's do-not-disturb window.
+// A window whose start is after its end wraps past midnight.
+type QuietHoursRequest struct {
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	Timezone  *string `json:"timezone,omitempty"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+}
+
+// Validate checks QuietHoursRequest fields.
+func (r *QuietHoursRequest) Validate() error {
+	if _, err := time.Parse("15:04", r.StartTime); err != nil {
+		return fmt.Errorf("start_time must be HH:MM (24-hour)")
+	}
+	if _, err := time.Parse("15:04", r.EndTime); err != nil {
+		return fmt.Errorf("end_time must be HH:MM (24-hour)")
+	}
+	if r.StartTime == r.EndTime {
+		return fmt.Errorf("start_time and end_time must differ")
+	}
+	if r.Timezone != nil {
+		if len(*r.Timezone) > 50 {
+			return fmt.Errorf("timezone exceeds 50 characters")
+		}
+		if _, err := time.LoadLocation(*r.Timezone); err != nil {
+			return fmt.Errorf("timezone must be an IANA timezone name")
+		}
+	}
+	return nil
+}
+
+// QuietHoursResponse represents a user's quiet-hours settings.
+type QuietHoursResponse struct {
+	UserID    string    `json:"user_id"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	Timezone  *string   `json:"timezone,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PreferencesRequest replaces a user's notification preferences.
+type PreferencesRequest struct {
+	Preferences []Preference `json:"preferences"`
+}
+
+// Preference enables or disables the notification types matching Type: an
+// exact type such as STOCK_REQUEST.REVIEWED, a prefix such as STOCK_REQUEST.*,
+// or * for every type. The most specific match wins.
+type Preference struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Validate checks PreferencesRequest fields.
+func (r *PreferencesRequest) Validate() error {
+	if len(r.Preferences) > 100 {
+		return fmt.Errorf("preferences exceeds maximum of 100 entries")
+	}
+	seen := make(map[string]bool, len(r.Preferences))
+	for i, p := range r.Preferences {
+		if strings.TrimSpace(p.Type) == "" {
+			return fmt.Errorf("preferences[%d].type is required", i)
+		}
+		if len(p.Type) > 50 {
+			return fmt.Errorf("preferences[%d].type exceeds 50 characters", i)
+		}
+		if p.Type != "*" && strings.Contains(strings.TrimSuffix(p.Type, ".*"), "*") {
+			return fmt.Errorf("preferences[%d].type may only end in .* or be *", i)
+		}
+		if seen[p.Type] {
+			return fmt.Errorf("preferences[%d].type is duplicated", i)
+		}
+		seen[p.Type] = true
+	}
+	return nil
+}
+
+// PreferencesResponse represents a user's notification preferences.
+type PreferencesResponse struct {
+	UserID      string       `json:"user_id"`
+	Preferences []Preference `json:"preferences"`
+}
+
+// ContactPointRequest registers an address for a non-push channel: an email
+// address, or an HTTPS URL for webhooks.
+type ContactPointRequest struct {
+	Channel string  `json:"channel"`
+	Address string  `json:"address"`
+	Locale  *string `json:"locale,omitempty"`
+}
+
+// Validate checks ContactPointRequest fields.
+func (r *ContactPointRequest) Validate() error {
+	switch r.Channel {
+	case channel.Email:
+		if len(r.Address) > 320 {
+			return fmt.Errorf("address exceeds 320 characters")
+		}
+		if addr, err := mail.ParseAddress(r.Address); err != nil || addr.Address != r.Address {
+			return fmt.Errorf("address must be a bare email address")
+		}
+	case channel.Webhook:
+		if len(r.Address) > maxWebhookURLLength {
+			return fmt.Errorf("address exceeds %d characters", maxWebhookURLLength)
+		}
+		if err := webhook.ValidateURL(r.Address); err != nil {
+			return fmt.Errorf("address: %w", err)
+		}
+	default:
+		return fmt.Errorf("channel must be one of: email, webhook")
+	}
+	if r.Locale != nil && len(*r.Locale) > 10 {
+		return fmt.Errorf("locale exceeds 10 characters")
+	}
+	return nil
+}
+
+// ContactPointResponse represents a registered contact point.
+type ContactPointResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    string    `json:"user_id"`
+	Channel   string    `json:"channel"`
+	Address   string    `json:"address"`
+	Locale    *string   `json:"locale,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	Secret    *string   `json:"secret,omitempty"` // Webhook signing secret, only returned on creation
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListContactPointsResponse represents a user's contact points.
+type ListContactPointsResponse struct {
+	UserID        string                 `json:"user_id"`
+	ContactPoints []ContactPointResponse `json:"contact_points"`
+}
+
+// RoutingPolicyRequest sets the fallback for a notification type: recipients
+// whose push deliveries all fail are sent FallbackChannel after the delay.
+type RoutingPolicyRequest struct {
+	FallbackChannel      string `json:"fallback_channel"`
+	FallbackDelaySeconds int    `json:"fallback_delay_seconds"`
+}
+
+// Validate checks RoutingPolicyRequest fields.
+func (r *RoutingPolicyRequest) Validate() error {
+	if r.FallbackChannel == channel.Push || !channel.Valid(r.FallbackChannel) {
+		return fmt.Errorf("fallback_channel must be one of: email, webhook")
+	}
+	if r.FallbackDelaySeconds < 0 || r.FallbackDelaySeconds > 86400 {
+		return fmt.Errorf("fallback_delay_seconds must be between 0 and 86400 (24 hours)")
+	}
+	return nil
+}
+
+// RoutingPolicyResponse represents a notification type's routing policy.
+type RoutingPolicyResponse struct {
+	Type                 string    `json:"type"`
+	FallbackChannel      string    `json:"fallback_channel"`
+	FallbackDelaySeconds int       `json:"fallback_delay_seconds"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// ListRoutingPoliciesResponse represents all routing policies.
+type ListRoutingPoliciesResponse struct {
+	Policies []RoutingPolicyResponse `json:"policies"`
+}
+
+// maxTopicsPerNotification caps how many topics a single notification targets
+const maxTopicsPerNotification = 20
+
+// validateTopicName checks a topic name such as warehouse.central.
+func validateTopicName(name string) error {
+	if name == "" {
+		return fmt.Errorf("topic name is required")
+	}
+	if len(name) > 100 {
+		return fmt.Errorf("topic name exceeds 100 characters")
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '.' && c != '-' && c != '_' {
+			return fmt.Errorf("topic name may only contain a-z, 0-9, '.', '-' and '_'")
+		}
+	}
+	return nil
+}
+
+// maxMetadataKeys caps the attributes on a subscription or segment
+const maxMetadataKeys = 20
+
+// validateSegment checks a segment has at least one bounded criterion.
+func validateSegment(s *queue.Segment) error {
+	if s.Locale == nil && len(s.Metadata) == 0 {
+		return fmt.Errorf("locale or metadata is required")
+	}
+	if s.Locale != nil && len(*s.Locale) > 10 {
+		return fmt.Errorf("locale exceeds 10 characters")
+	}
+	if len(s.Metadata) > maxMetadataKeys {
+		return fmt.Errorf("metadata exceeds maximum of %d keys", maxMetadataKeys)
+	}
+	for key, value := range s.Metadata {
+		if strings.TrimSpace(key) == "" || len(key) > 100 {
+			return fmt.Errorf("metadata keys must be 1-100 characters")
+		}
+		if len(value) > 255 {
+			return fmt.Errorf("metadata.%s exceeds 255 characters", key)
+		}
+	}
+	return nil
+}
+
+// CreateTopicRequest represents a new topic.
+type CreateTopicRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// Validate checks CreateTopicRequest fields.
+func (r *CreateTopicRequest) Validate() error {
+	if err := validateTopicName(r.Name); err != nil {
+		return err
+	}
+	if r.Description != nil && len(*r.Description) > 500 {
+		return fmt.Errorf("description exceeds 500 characters")
+	}
+	return nil
+}
+
+// TopicResponse represents a topic.
+type TopicResponse struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListTopicsResponse represents a page of topics.
+type ListTopicsResponse struct {
+	Topics []TopicResponse `json:"topics"`
+	Total  int             `json:"total"`
+}
+
+// TopicSubscriberResponse represents a user subscribed to a topic.
+type TopicSubscriberResponse struct {
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListTopicSubscribersResponse represents a page of topic subscribers.
+type ListTopicSubscribersResponse struct {
+	Topic       string                    `json:"topic"`
+	Subscribers []TopicSubscriberResponse `json:"subscribers"`
+	Total       int                       `json:"total"`
+}
+
+// InboxItemResponse represents a recipient's in-app copy of a notification,
+// rendered like the push payload.
+type InboxItemResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	NotificationID uuid.UUID       `json:"notification_id"`
+	Type           string          `json:"type"`
+	Locale         string          `json:"locale"`
+	Title          string          `json:"title,omitempty"`
+	Body           string          `json:"body,omitempty"`
+	Icon           string          `json:"icon,omitempty"`
+	URL            string          `json:"url,omitempty"`
+	Data           json.RawMessage `json:"data,omitempty"`
+	Read           bool            `json:"read"`
+	ReadAt         *time.Time      `json:"read_at,omitempty"`
+	ArchivedAt     *time.Time      `json:"archived_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ListInboxResponse represents a page of a user's inbox. UnreadCount covers
+// the whole inbox, not just this page.
+type ListInboxResponse struct {
+	UserID      string              `json:"user_id"`
+	Items       []InboxItemResponse `json:"items"`
+	UnreadCount int                 `json:"unread_count"`
+	NextCursor  string              `json:"next_cursor,omitempty"`
+}
+
+// MarkAllInboxItemsReadResponse reports how many inbox items were marked read.
+type MarkAllInboxItemsReadResponse struct {
+	UserID  string `json:"user_id"`
+	Updated int    `json:"updated"`
+}
+
+// StreamTokenResponse carries a token that opens one user's inbox stream or
+// WebSocket until ExpiresAt.
+type StreamTokenResponse struct {
+	UserID    string    `json:"user_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SocketMessage is a message sent by a client over the inbox WebSocket. The
+// only type is ack, reporting a client-side event for a notification the
+// user received.
+type SocketMessage struct {
+	Type           string `json:"type"`
+	Event          string `json:"event"`
+	NotificationID string `json:"notification_id"`
+}
+
+// Validate checks SocketMessage fields.
+func (m *SocketMessage) Validate() error {
+	if m.Type != socketMessageAck {
+		return fmt.Errorf("type must be %s", socketMessageAck)
+	}
+	if !slices.Contains(ackEvents, m.Event) {
+		return fmt.Errorf("event must be one of: %s", strings.Join(ackEvents, ", "))
+	}
+	if _, err := uuid.Parse(m.NotificationID); err != nil {
+		return fmt.Errorf("notification_id must be a UUID")
+	}
+	return nil
+}
+
+// SocketNotification carries a new inbox item to a WebSocket client. ID is
+// the item's inbox cursor, for resuming with last_event_id.
+type SocketNotification struct {
+	Type string            `json:"type"`
+	ID   string            `json:"id"`
+	Item InboxItemResponse `json:"item"`
+}
+
+// SocketAck confirms a client's ack. Recorded is false when the event was
+// already recorded for the notification.
+type SocketAck struct {
+	Type           string    `json:"type"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	Event          string    `json:"event"`
+	Recorded       bool      `json:"recorded"`
+}
+
+// SocketError reports a client message that was rejected; the socket stays open.
+type SocketError struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// maxWebhookURLLength caps webhook URLs on contact points and topics
+const maxWebhookURLLength = 2048
+
+// TopicWebhookRequest registers an HTTPS endpoint for a topic.
+type TopicWebhookRequest struct {
+	URL string `json:"url"`
+}
+
+// Validate checks TopicWebhookRequest fields.
+func (r *TopicWebhookRequest) Validate() error {
+	if len(r.URL) > maxWebhookURLLength {
+		return fmt.Errorf("url exceeds %d characters", maxWebhookURLLength)
+	}
+	return webhook.ValidateURL(r.URL)
+}
+
+// TopicWebhookResponse represents a topic webhook.
+type TopicWebhookResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Topic     string    `json:"topic"`
+	URL       string    `json:"url"`
+	IsActive  bool      `json:"is_active"`
+	Secret    string    `json:"secret,omitempty"` // Signing secret, only returned on creation
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListTopicWebhooksResponse represents a topic's webhooks.
+type ListTopicWebhooksResponse struct {
+	Topic    string                 `json:"topic"`
+	Webhooks []TopicWebhookResponse `json:"webhooks"`
+}
+
 // HealthResponse represents health check response.
 type HealthResponse struct {
 	Status    string            `json:"status"`
@@ -215,3 +905,157 @@ type HealthResponse struct {
 type VAPIDPublicKeyResponse struct {
 	PublicKey string `json:"public_key"`
 }
+
+// DeadLetterResponse represents a task that exhausted its retries.
+// Delivery fields are set for notification:deliver tasks.
+type DeadLetterResponse struct {
+	ID             string          `json:"id"`
+	Queue          string          `json:"queue"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	LastError      string          `json:"last_error"`
+	LastFailedAt   time.Time       `json:"last_failed_at"`
+	Retried        int             `json:"retried"`
+	MaxRetry       int             `json:"max_retry"`
+	NotificationID *uuid.UUID      `json:"notification_id,omitempty"`
+	SubscriptionID *uuid.UUID      `json:"subscription_id,omitempty"`
+	ContactPointID *uuid.UUID      `json:"contact_point_id,omitempty"`
+	TopicWebhookID *uuid.UUID      `json:"topic_webhook_id,omitempty"`
+	Channel        *string         `json:"channel,omitempty"`
+	UserID         *string         `json:"user_id,omitempty"`
+}
+
+// ListDeadLettersResponse represents a page of dead letters.
+type ListDeadLettersResponse struct {
+	DeadLetters []DeadLetterResponse `json:"dead_letters"`
+	Total       int                  `json:"total"`
+}
+
+// DeadLetterBulkRequest selects dead letters to requeue or purge.
+// Without task_ids, every dead letter in queue (or in all queues) is selected.
+type DeadLetterBulkRequest struct {
+	Queue   string   `json:"queue,omitempty"`
+	TaskIDs []string `json:"task_ids,omitempty"`
+}
+
+// Validate checks DeadLetterBulkRequest fields.
+func (r *DeadLetterBulkRequest) Validate() error {
+	if len(r.TaskIDs) > 0 && r.Queue == "" {
+		return fmt.Errorf("queue is required when task_ids are given")
+	}
+	if len(r.TaskIDs) > maxBulkDeadLetters {
+		return fmt.Errorf("task_ids must contain at most %d entries", maxBulkDeadLetters)
+	}
+	return nil
+}
+
+// DeadLetterBulkResponse reports the outcome of a bulk requeue or purge.
+type DeadLetterBulkResponse struct {
+	Processed int                 `json:"processed"`
+	Failed    []DeadLetterFailure `json:"failed"`
+}
+
+// DeadLetterFailure describes a dead letter that couldn't be processed.
+type DeadLetterFailure struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"`
+}
+
+// CreateAPIClientRequest registers a producer. Its key ID and first secret
+// are generated. CallbackURL receives callbacks for the client's
+// notifications that don't name their own callback_url.
+type CreateAPIClientRequest struct {
+	Name        string   `json:"name"`
+	Scopes      []string `json:"scopes"`
+	CallbackURL *string  `json:"callback_url,omitempty"`
+}
+
+// Validate checks CreateAPIClientRequest fields.
+func (r *CreateAPIClientRequest) Validate() error {
+	if err := validateAPIClientName(r.Name); err != nil {
+		return err
+	}
+	if err := validateAPIClientScopes(r.Scopes); err != nil {
+		return err
+	}
+	if r.CallbackURL != nil {
+		return validateCallbackURL(*r.CallbackURL)
+	}
+	return nil
+}
+
+// UpdateAPIClientRequest changes a client's name, scopes, status or callback
+// URL; omitted fields are left as they are and an empty callback_url clears it.
+type UpdateAPIClientRequest struct {
+	Name        *string  `json:"name,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	Status      *string  `json:"status,omitempty"`
+	CallbackURL *string  `json:"callback_url,omitempty"`
+}
+
+// Validate checks UpdateAPIClientRequest fields.
+func (r *UpdateAPIClientRequest) Validate() error {
+	if r.Name != nil {
+		if err := validateAPIClientName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Scopes != nil {
+		if err := validateAPIClientScopes(r.Scopes); err != nil {
+			return err
+		}
+	}
+	if r.Status != nil && *r.Status != apiClientActive && *r.Status != apiClientDisabled {
+		return fmt.Errorf("status must be %s or %s", apiClientActive, apiClientDisabled)
+	}
+	if r.CallbackURL != nil && *r.CallbackURL != "" {
+		return validateCallbackURL(*r.CallbackURL)
+	}
+	return nil
+}
+
+func validateAPIClientName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(name) > 100 {
+		return fmt.Errorf("name exceeds 100 characters")
+	}
+	return nil
+}
+
+func validateAPIClientScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("scopes is required")
+	}
+	for _, s := range scopes {
+		if !slices.Contains(auth.Scopes, s) {
+			return fmt.Errorf("unknown scope %q", s)
+		}
+	}
+	return nil
+}
+
+// APIClientResponse represents an API client.
+type APIClientResponse struct {
+	KeyID       string                    `json:"key_id"`
+	Name        string                    `json:"name"`
+	Scopes      []string                  `json:"scopes"`
+	Status      string                    `json:"status"`
+	CallbackURL *string                   `json:"callback_url,omitempty"`
+	Secrets     []APIClientSecretResponse `json:"secrets,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// ListAPIClientsResponse represents every API client.
+type ListAPIClientsResponse struct {
+	Clients []APIClientResponse `json:"clients"`
+}
+
+// APIClientSecretResponse represents one of a client's secrets.
+type APIClientSecretResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Secret    string    `json:"secret,omitempty"` // Only returned on creation
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,203 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// Client-side events recorded in notification_events
+const (
+	eventDelivered = "delivered"
+	eventDisplayed = "displayed"
+	eventClicked   = "clicked"
+	eventClosed    = "closed"
+)
+
+// ackEvents are the events clients may acknowledge over the inbox WebSocket
+var ackEvents = []string{eventDelivered, eventDisplayed, eventClicked}
+
+// reportedEvents maps the events service workers report to POST /v1/events
+// to the events recorded, so push displays count alongside in-app ones
+var reportedEvents = map[string]string{
+	"shown":   eventDisplayed,
+	"clicked": eventClicked,
+	"closed":  eventClosed,
+}
+
+// Sources recorded with each event
+const (
+	eventSourceWebSocket     = "websocket"
+	eventSourceRedirect      = "redirect"
+	eventSourceServiceWorker = "service_worker"
+)
+
+// maxReportEventBody caps POST /v1/events bodies, which are public
+const maxReportEventBody = 4096
+
+// defaultEngagementWindow is how far back engagement is reported without since
+const defaultEngagementWindow = 30 * 24 * time.Hour
+
+// errNotRecipient is returned for events about a notification the user never received
+var errNotRecipient = errors.New("user is not a recipient of the notification")
+
+// recordNotificationEvent stores a client-side event for one of the user's
+// notifications. Each event counts once per recipient, so repeats report
+// recorded=false.
+func (h *Handler) recordNotificationEvent(ctx context.Context, notifID uuid.UUID, userID, event, source string) (bool, error) {
+	ok, err := h.repo.IsNotificationRecipient(ctx, repo.IsNotificationRecipientParams{
+		NotificationID: notifID,
+		UserID:         userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check recipient: %w", err)
+	}
+	if !ok {
+		return false, errNotRecipient
+	}
+
+	n, err := h.repo.CreateNotificationEvent(ctx, repo.CreateNotificationEventParams{
+		NotificationID: notifID,
+		UserID:         userID,
+		Event:          event,
+		Source:         source,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create event: %w", err)
+	}
+	return n > 0, nil
+}
+
+// TrackClick handles GET /v1/t/:token, the signed redirect push links are
+// rewritten to. It records a click by the token's recipient, then redirects
+// to the original URL; a click that can't be recorded still redirects.
+func (h *Handler) TrackClick(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	if h.tracker == nil {
+		h.respondError(w, http.StatusNotFound, "link not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/t/:token", 404)
+		return
+	}
+	claims, err := h.tracker.Parse(chi.URLParam(r, "token"))
+	if err != nil || claims.URL == "" {
+		h.respondError(w, http.StatusNotFound, "link not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/t/:token", 404)
+		return
+	}
+
+	if _, err := h.recordNotificationEvent(ctx, claims.NotificationID, claims.UserID, eventClicked, eventSourceRedirect); err != nil {
+		h.logger.Warn("failed to record click", zap.Error(err),
+			zap.String("notification_id", claims.NotificationID.String()), zap.String("user_id", claims.UserID))
+	}
+
+	http.Redirect(w, r, claims.URL, http.StatusFound)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/t/:token", 302)
+	metrics.ObserveRequestDuration("GET", "/v1/t/:token", 302, time.Since(start).Seconds())
+}
+
+// ReportEvent handles POST /v1/events, where service workers report shown,
+// clicked and closed events for a push notification using its tracking token
+func (h *Handler) ReportEvent(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	if h.tracker == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "tracking not configured", "NOT_CONFIGURED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/events", 503)
+		return
+	}
+
+	var req ReportEventRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReportEventBody)).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/events", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/events", 400)
+		return
+	}
+	claims, err := h.tracker.Parse(req.Token)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "invalid tracking token", "INVALID_TOKEN", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/events", 401)
+		return
+	}
+
+	_, err = h.recordNotificationEvent(ctx, claims.NotificationID, claims.UserID, reportedEvents[req.Event], eventSourceServiceWorker)
+	if errors.Is(err, errNotRecipient) {
+		h.respondError(w, http.StatusNotFound, "notification not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/events", 404)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to record notification event", zap.Error(err),
+			zap.String("notification_id", claims.NotificationID.String()), zap.String("user_id", claims.UserID))
+		h.respondError(w, http.StatusInternalServerError, "failed to record event", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/events", 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/events", 204)
+	metrics.ObserveRequestDuration("POST", "/v1/events", 204, time.Since(start).Seconds())
+}
+
+// GetEngagementByType handles GET /v1/analytics/engagement, open and click
+// rates per notification type for notifications created since the since
+// query parameter (RFC 3339, default 30 days ago)
+func (h *Handler) GetEngagementByType(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	since := time.Now().Add(-defaultEngagementWindow)
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp", "VALIDATION_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("GET", "/v1/analytics/engagement", 400)
+			return
+		}
+		since = t
+	}
+
+	rows, err := h.repo.ListEngagementByType(ctx, since)
+	if err != nil {
+		h.logger.Error("failed to list engagement", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/analytics/engagement", 500)
+		return
+	}
+
+	types := make([]TypeEngagement, len(rows))
+	for i, row := range rows {
+		types[i] = TypeEngagement{
+			Type:      row.Type,
+			Delivered: int(row.Delivered),
+			Displayed: int(row.Displayed),
+			Clicked:   int(row.Clicked),
+			Closed:    int(row.Closed),
+		}
+		if row.Delivered > 0 {
+			types[i].OpenRate = float64(row.Displayed) / float64(row.Delivered)
+			types[i].ClickRate = float64(row.Clicked) / float64(row.Delivered)
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, EngagementResponse{Since: since, Types: types})
+	metrics.IncHTTPRequestsTotal("GET", "/v1/analytics/engagement", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/analytics/engagement", 200, time.Since(start).Seconds())
+}
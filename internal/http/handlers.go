@@ -4,32 +4,69 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 
+	"notifications/internal/auth"
+	"notifications/internal/channel"
 	"notifications/internal/metrics"
 	"notifications/internal/queue"
 	"notifications/internal/repo"
+	"notifications/internal/stream"
+	"notifications/internal/templates"
+	"notifications/internal/tracking"
 )
 
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
-	repo        *repo.Repository
-	logger      *zap.Logger
-	queueClient *queue.Client
+	repo         *repo.Repository
+	logger       *zap.Logger
+	queueClient  *queue.Client
+	inspector    *queue.Inspector
+	resolver     *templates.Resolver
+	hub          *stream.Hub
+	tracker      *tracking.Signer
+	streamTokens *stream.TokenSigner
+	secretCipher *auth.SecretCipher
+
+	socketOrigins        []string
+	idempotencyRetention time.Duration
+	defaultCallbackURL   string
 }
 
-// NewHandler creates a new Handler.
-func NewHandler(r *repo.Repository, queueClient *queue.Client, logger *zap.Logger) *Handler {
+// NewHandler creates a new Handler. Idempotency keys are held for
+// idempotencyRetention before they can be reused. hub relays inbox events to
+// open streams. tracker verifies click and event tokens; nil disables tracking.
+// streamTokens issues and verifies inbox stream tokens; nil disables streams.
+// secretCipher encrypts new API client secrets; nil disables creating them.
+// Browsers may open WebSockets from socketOrigins as well as the API's own.
+// Notifications without a callback_url, from a client without one either, use
+// defaultCallbackURL, if set.
+func NewHandler(r *repo.Repository, queueClient *queue.Client, inspector *queue.Inspector, resolver *templates.Resolver, hub *stream.Hub, tracker *tracking.Signer, streamTokens *stream.TokenSigner, secretCipher *auth.SecretCipher, socketOrigins []string, idempotencyRetention time.Duration, defaultCallbackURL string, logger *zap.Logger) *Handler {
 	return &Handler{
-		repo:        r,
-		logger:      logger,
-		queueClient: queueClient,
+		repo:         r,
+		logger:       logger,
+		queueClient:  queueClient,
+		inspector:    inspector,
+		resolver:     resolver,
+		hub:          hub,
+		tracker:      tracker,
+		streamTokens: streamTokens,
+		secretCipher: secretCipher,
+
+		socketOrigins:        socketOrigins,
+		idempotencyRetention: idempotencyRetention,
+		defaultCallbackURL:   defaultCallbackURL,
 	}
 }
 
@@ -80,6 +117,14 @@ func (h *Handler) RegisterSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metadataJSON, err := req.MetadataAsJSON()
+	if err != nil {
+		h.logger.Warn("failed to marshal subscription metadata", zap.Error(err))
+		h.respondError(w, http.StatusBadRequest, "invalid metadata field", "INVALID_METADATA", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/subscriptions", 400)
+		return
+	}
+
 	// Create new subscription (ID is auto-generated by database)
 	sub, err := h.repo.CreateDeviceSubscription(ctx, repo.CreateDeviceSubscriptionParams{
 		UserID:    req.UserID,
@@ -90,6 +135,7 @@ func (h *Handler) RegisterSubscription(w http.ResponseWriter, r *http.Request) {
 		UserAgent: req.UserAgent,
 		Locale:    req.Locale,
 		Timezone:  req.Timezone,
+		Metadata:  metadataJSON,
 	})
 	if err != nil {
 		h.logger.Error("failed to create subscription", zap.Error(err), zap.String("user_id", req.UserID))
@@ -179,30 +225,41 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check idempotency
+	// Check idempotency: a held key replays the stored notification, or
+	// conflicts when the payload differs. Keys are scoped to the producer.
+	var requestHash *string
+	var idempotencyExpiresAt *time.Time
+	idempotencyScope := auth.SharedKeyID
+	if client, ok := auth.ClientFromContext(ctx); ok {
+		idempotencyScope = client.KeyID
+	}
+	idempotencyKey := repo.GetNotificationByIdempotencyKeyParams{CreatedBy: idempotencyScope}
 	if req.IdempotencyKey != nil {
-		existing, err := h.repo.GetNotificationByIdempotencyKey(ctx, req.IdempotencyKey)
-		if err == nil {
-			// Already exists, return it
-			recipientCount, _ := h.repo.CountRecipientsByNotification(ctx, existing.ID)
-			h.logger.Info("notification already exists (idempotency)", zap.String("notification_id", existing.ID.String()))
-			resp := SendNotificationResponse{
-				ID:             existing.ID,
-				Type:           existing.Type,
-				Status:         existing.Status,
-				RecipientCount: int(recipientCount),
-				CreatedAt:      existing.CreatedAt,
-			}
-			h.respondJSON(w, http.StatusOK, resp)
-			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 200)
+		idempotencyKey.IdempotencyKey = *req.IdempotencyKey
+		hash, err := req.RequestHash()
+		if err != nil {
+			h.logger.Error("failed to hash send notification request", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 500)
 			return
 		}
-		if !errors.Is(err, pgx.ErrNoRows) {
-			h.logger.Error("failed to check idempotency key", zap.Error(err))
+		expiresAt := time.Now().Add(h.idempotencyRetention)
+		requestHash = &hash
+		idempotencyExpiresAt = &expiresAt
+
+		// Expired keys are released so the request creates a new notification
+		if _, err := h.repo.ReleaseExpiredIdempotencyKey(ctx, repo.ReleaseExpiredIdempotencyKeyParams{
+			CreatedBy:      idempotencyKey.CreatedBy,
+			IdempotencyKey: idempotencyKey.IdempotencyKey,
+		}); err != nil {
+			h.logger.Error("failed to release expired idempotency key", zap.Error(err))
 			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
 			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 500)
 			return
 		}
+		if h.replayIdempotentRequest(ctx, w, idempotencyKey, hash) {
+			return
+		}
 	}
 
 	// Convert data to JSON
@@ -214,6 +271,78 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Topic members are resolved at fan-out, but the topics must exist now
+	var topics []string
+	if len(req.Topics) > 0 {
+		topics = slices.Compact(slices.Sorted(slices.Values(req.Topics)))
+		found, err := h.repo.CountExistingTopics(ctx, topics)
+		if err != nil {
+			h.logger.Error("failed to check topics", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 500)
+			return
+		}
+		if int(found) != len(topics) {
+			h.respondError(w, http.StatusBadRequest, "topics contains an unknown topic", "UNKNOWN_TOPIC", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 400)
+			return
+		}
+	}
+
+	// Segment members are resolved at fan-out from subscription attributes
+	var segmentJSON json.RawMessage
+	if req.Segment != nil {
+		segmentJSON, err = json.Marshal(req.Segment)
+		if err != nil {
+			h.logger.Warn("failed to marshal segment", zap.Error(err))
+			h.respondError(w, http.StatusBadRequest, "invalid segment field", "INVALID_SEGMENT", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 400)
+			return
+		}
+	}
+
+	// Pin the template version used for the request locale so the rendered copy
+	// is auditable; explicit title and body bypass templates entirely. Other
+	// locales are recorded per delivery attempt.
+	var templateID pgtype.UUID
+	var templateVersion *int32
+	if req.Title == nil || req.Body == nil {
+		content, err := h.resolver.Resolve(ctx, req.Type, req.Data, req.Locale)
+		var missing *templates.MissingVariablesError
+		if err != nil && !errors.Is(err, templates.ErrTemplateNotFound) && !errors.As(err, &missing) {
+			h.logger.Error("failed to resolve template", zap.Error(err), zap.String("type", req.Type))
+			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 500)
+			return
+		}
+		if content != nil && content.TemplateID != nil {
+			templateID = pgtype.UUID{Bytes: *content.TemplateID, Valid: true}
+			templateVersion = content.TemplateVersion
+		}
+	}
+
+	// A future send_at defers fan-out; a past one sends right away
+	status := queue.NotificationStatusQueued
+	var processAt *time.Time
+	if req.SendAt != nil && req.SendAt.After(time.Now()) {
+		status = queue.NotificationStatusScheduled
+		processAt = req.SendAt
+	}
+
+	// Record which producer sent the notification. Without a callback_url,
+	// callbacks go to the producer's own callback URL, then the default.
+	callbackURL := req.CallbackURL
+	var createdBy *string
+	if client, ok := auth.ClientFromContext(ctx); ok {
+		createdBy = &client.KeyID
+		if callbackURL == nil && client.CallbackURL != "" {
+			callbackURL = &client.CallbackURL
+		}
+	}
+	if callbackURL == nil && h.defaultCallbackURL != "" {
+		callbackURL = &h.defaultCallbackURL
+	}
+
 	// Create notification and recipients in a transaction
 	var notif repo.Notification
 	var recipientCount int
@@ -226,41 +355,73 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 		}
 
 		notif, err = q.CreateNotification(ctx, repo.CreateNotificationParams{
-			IdempotencyKey: req.IdempotencyKey,
-			Type:           req.Type,
-			Title:          req.Title,
-			Body:           req.Body,
-			Icon:           req.Icon,
-			Url:            req.URL,
-			Locale:         req.Locale,
-			Data:           dataJSON,
-			DedupeKey:      req.DedupeKey,
-			TtlSeconds:     &ttl,
-			Priority:       req.Priority,
+			IdempotencyKey:       req.IdempotencyKey,
+			Type:                 req.Type,
+			Title:                req.Title,
+			Body:                 req.Body,
+			Icon:                 req.Icon,
+			Url:                  req.URL,
+			Locale:               req.Locale,
+			Data:                 dataJSON,
+			Status:               status,
+			DedupeKey:            req.DedupeKey,
+			TtlSeconds:           &ttl,
+			Priority:             req.Priority,
+			TemplateID:           templateID,
+			TemplateVersion:      templateVersion,
+			RequestHash:          requestHash,
+			IdempotencyExpiresAt: idempotencyExpiresAt,
+			SendAt:               req.SendAt,
+			DeliverLocalTime:     req.DeliverLocalTime,
+			Topics:               topics,
+			Segment:              segmentJSON,
+			Channels:             slices.Compact(slices.Sorted(slices.Values(req.Channels))),
+			CallbackUrl:          callbackURL,
+			CallbackAttempts:     req.CallbackAttempts && callbackURL != nil,
+			CreatedBy:            createdBy,
 		})
 		if err != nil {
 			return err
 		}
 
-		// Create recipients
-		recipientParams := make([]repo.CreateRecipientsBatchParams, len(req.UserIDs))
-		for i, userID := range req.UserIDs {
-			recipientParams[i] = repo.CreateRecipientsBatchParams{
-				NotificationID: notif.ID,
-				UserID:         userID,
+		// Create recipients (topic and segment notifications may have none)
+		if len(req.UserIDs) > 0 {
+			recipientParams := make([]repo.CreateRecipientsBatchParams, len(req.UserIDs))
+			for i, userID := range req.UserIDs {
+				recipientParams[i] = repo.CreateRecipientsBatchParams{
+					NotificationID: notif.ID,
+					UserID:         userID,
+				}
 			}
+
+			inserted, err := q.CreateRecipientsBatch(ctx, recipientParams)
+			if err != nil {
+				return err
+			}
+			recipientCount = int(inserted)
 		}
 
-		inserted, err := q.CreateRecipientsBatch(ctx, recipientParams)
+		// Fan-out runs in the worker; the outbox row commits with the
+		// notification and is published to the queue by the worker's relay
+		priority := queue.PriorityNormal
+		if req.Priority != nil {
+			priority = *req.Priority
+		}
+		outbox, err := queue.NewFanoutOutboxMessage(notif.ID, priority, processAt)
 		if err != nil {
 			return err
 		}
-		recipientCount = int(inserted)
-
-		return nil
+		_, err = q.CreateOutboxMessage(ctx, outbox)
+		return err
 	})
 
 	if err != nil {
+		// A concurrent request with the same key won the insert
+		var pgErr *pgconn.PgError
+		if req.IdempotencyKey != nil && errors.As(err, &pgErr) && pgErr.Code == "23505" &&
+			h.replayIdempotentRequest(ctx, w, idempotencyKey, *requestHash) {
+			return
+		}
 		h.logger.Error("failed to create notification", zap.Error(err), zap.String("type", req.Type))
 		h.respondError(w, http.StatusInternalServerError, "failed to create notification", "CREATE_FAILED", nil)
 		metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 500)
@@ -273,24 +434,12 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 		zap.Int("recipients", recipientCount),
 	)
 
-	// Enqueue delivery tasks for each recipient
-	priority := queue.PriorityNormal
-	if req.Priority != nil {
-		priority = *req.Priority
-	}
-	ttl := 3600 // Default 1 hour
-	if req.TTLSeconds != nil {
-		ttl = *req.TTLSeconds
-	}
-
-	// Enqueue tasks asynchronously for each recipient's active subscriptions
-	go h.enqueueDeliveryTasks(ctx, notif.ID, req.UserIDs, priority, ttl)
-
 	resp := SendNotificationResponse{
 		ID:             notif.ID,
 		Type:           notif.Type,
 		Status:         notif.Status,
 		RecipientCount: recipientCount,
+		SendAt:         notif.SendAt,
 		CreatedAt:      notif.CreatedAt,
 	}
 
@@ -300,6 +449,49 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 	metrics.IncNotificationsSent(notif.Type)
 }
 
+// replayIdempotentRequest responds for a notification the same producer
+// already sent with the idempotency key: 200 with the stored notification
+// when the request hash matches, 409 IDEMPOTENCY_CONFLICT otherwise. It
+// returns false, without responding, when no notification holds the key.
+func (h *Handler) replayIdempotentRequest(ctx context.Context, w http.ResponseWriter, key repo.GetNotificationByIdempotencyKeyParams, hash string) bool {
+	existing, err := h.repo.GetNotificationByIdempotencyKey(ctx, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		h.logger.Error("failed to check idempotency key", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 500)
+		return true
+	}
+
+	// Notifications created before hashing was introduced have no hash to compare
+	if existing.RequestHash != nil && *existing.RequestHash != hash {
+		h.logger.Warn("idempotency key reused with a different payload",
+			zap.String("notification_id", existing.ID.String()),
+		)
+		h.respondError(w, http.StatusConflict, "idempotency_key was already used with a different request", "IDEMPOTENCY_CONFLICT", map[string]string{
+			"notification_id": existing.ID.String(),
+		})
+		metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 409)
+		return true
+	}
+
+	recipientCount, _ := h.repo.CountRecipientsByNotification(ctx, existing.ID)
+	h.logger.Info("notification already exists (idempotency)", zap.String("notification_id", existing.ID.String()))
+	resp := SendNotificationResponse{
+		ID:             existing.ID,
+		Type:           existing.Type,
+		Status:         existing.Status,
+		RecipientCount: int(recipientCount),
+		SendAt:         existing.SendAt,
+		CreatedAt:      existing.CreatedAt,
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/notifications", 200)
+	return true
+}
+
 // GetNotification handles GET /v1/notifications/:id
 func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
@@ -337,19 +529,115 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 	if len(notif.Data) > 0 {
 		_ = json.Unmarshal(notif.Data, &data)
 	}
+	channels := notif.Channels
+	if len(channels) == 0 {
+		channels = []string{channel.Push}
+	}
+	var segment *queue.Segment
+	if len(notif.Segment) > 0 {
+		segment = &queue.Segment{}
+		_ = json.Unmarshal(notif.Segment, segment)
+	}
+
+	// Include the pinned template version so callers can see the exact copy
+	var tmplResp *TemplateResponse
+	if notif.TemplateID.Valid {
+		tmpl, err := h.repo.GetTemplate(ctx, uuid.UUID(notif.TemplateID.Bytes))
+		if err != nil {
+			h.logger.Error("failed to get notification template", zap.Error(err))
+		} else {
+			t := toTemplateResponse(tmpl)
+			tmplResp = &t
+		}
+	}
+
+	// Per-status counts use the latest attempt for each subscription
+	deliveryCounts, err := h.repo.GetNotificationDeliveryCounts(ctx, notifID)
+	if err != nil {
+		h.logger.Error("failed to get delivery counts", zap.Error(err), zap.String("notification_id", idStr))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/notifications/:id", 500)
+		return
+	}
+	counts := DeliveryCounts{
+		Delivered: int(deliveryCounts.Delivered),
+		Failed:    int(deliveryCounts.Failed),
+		Pruned:    int(deliveryCounts.Pruned),
+		Skipped:   int(deliveryCounts.Skipped),
+		Retrying:  int(deliveryCounts.Retrying),
+		Deferred:  int(deliveryCounts.Deferred),
+	}
+
+	eventCounts, err := h.repo.GetNotificationEventCounts(ctx, notifID)
+	if err != nil {
+		h.logger.Error("failed to get event counts", zap.Error(err), zap.String("notification_id", idStr))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/notifications/:id", 500)
+		return
+	}
+	engagement := EngagementCounts{
+		Delivered: int(eventCounts.Delivered),
+		Displayed: int(eventCounts.Displayed),
+		Clicked:   int(eventCounts.Clicked),
+		Closed:    int(eventCounts.Closed),
+	}
+
+	// Triggered fallbacks add their deliveries to the expected count
+	fallbacks, err := h.repo.ListFallbacksByNotification(ctx, notifID)
+	if err != nil {
+		h.logger.Error("failed to list fallbacks", zap.Error(err), zap.String("notification_id", idStr))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/notifications/:id", 500)
+		return
+	}
+	fallbackDeliveries := 0
+	fallbackResp := make([]FallbackResponse, len(fallbacks))
+	for i, fallback := range fallbacks {
+		fallbackDeliveries += int(fallback.Deliveries)
+		fallbackResp[i] = FallbackResponse{
+			ID:          fallback.ID,
+			UserID:      fallback.UserID,
+			Channel:     fallback.Channel,
+			Status:      fallback.Status,
+			Deliveries:  int(fallback.Deliveries),
+			ProcessAt:   fallback.ProcessAt,
+			TriggeredAt: fallback.TriggeredAt,
+		}
+	}
+
+	var expected *int
+	if notif.ExpectedDeliveries != nil {
+		n := int(*notif.ExpectedDeliveries) + fallbackDeliveries
+		expected = &n
+		counts.Pending = max(n-counts.Delivered-counts.Failed-counts.Pruned-counts.Skipped, 0)
+	}
 
 	resp := GetNotificationResponse{
-		ID:             notif.ID,
-		Type:           notif.Type,
-		Title:          notif.Title,
-		Body:           notif.Body,
-		Icon:           notif.Icon,
-		URL:            notif.Url,
-		Locale:         notif.Locale,
-		Data:           data,
-		Status:         notif.Status,
-		RecipientCount: int(recipientCount),
-		CreatedAt:      notif.CreatedAt,
+		ID:                 notif.ID,
+		Type:               notif.Type,
+		Title:              notif.Title,
+		Body:               notif.Body,
+		Icon:               notif.Icon,
+		URL:                notif.Url,
+		Locale:             notif.Locale,
+		Data:               data,
+		Status:             notif.Status,
+		RecipientCount:     int(recipientCount),
+		Topics:             notif.Topics,
+		Segment:            segment,
+		Channels:           channels,
+		ExpectedDeliveries: expected,
+		Counts:             counts,
+		Engagement:         engagement,
+		Fallbacks:          fallbackResp,
+		Template:           tmplResp,
+		SendAt:             notif.SendAt,
+		DeliverLocalTime:   notif.DeliverLocalTime,
+		CreatedAt:          notif.CreatedAt,
+		CompletedAt:        notif.CompletedAt,
+		CallbackURL:        notif.CallbackUrl,
+		CallbackAttempts:   notif.CallbackAttempts,
+		CreatedBy:          notif.CreatedBy,
 	}
 
 	h.respondJSON(w, http.StatusOK, resp)
@@ -357,6 +645,85 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 	metrics.ObserveRequestDuration("GET", "/v1/notifications/:id", 200, time.Since(start).Seconds())
 }
 
+// CancelNotification handles DELETE /v1/notifications/:id
+// Only scheduled notifications can be cancelled; their fan-out task is removed
+// from the outbox, or from the queue when it was already published.
+func (h *Handler) CancelNotification(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	notifID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid notification ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/notifications/:id", 400)
+		return
+	}
+
+	var cancelled int64
+	err = h.repo.WithTx(ctx, func(q *repo.Queries) error {
+		cancelled, err = q.CancelScheduledNotification(ctx, notifID)
+		if err != nil || cancelled == 0 {
+			return err
+		}
+		if _, err = q.DeletePendingOutboxMessages(ctx, notifID); err != nil {
+			return err
+		}
+		return queue.CreateCancelledCallback(ctx, q, notifID)
+	})
+	if err != nil {
+		h.logger.Error("failed to cancel notification", zap.Error(err), zap.String("notification_id", idStr))
+		h.respondError(w, http.StatusInternalServerError, "failed to cancel notification", "CANCEL_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/notifications/:id", 500)
+		return
+	}
+
+	if cancelled == 0 {
+		notif, err := h.repo.GetNotification(ctx, notifID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.respondError(w, http.StatusNotFound, "notification not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("DELETE", "/v1/notifications/:id", 404)
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to get notification", zap.Error(err), zap.String("notification_id", idStr))
+			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("DELETE", "/v1/notifications/:id", 500)
+			return
+		}
+		h.respondError(w, http.StatusConflict, "only scheduled notifications can be cancelled", "NOT_CANCELLABLE", map[string]string{
+			"status": notif.Status,
+		})
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/notifications/:id", 409)
+		return
+	}
+
+	// Fan-out tasks already published by the relay wait in asynq's scheduled
+	// set. If one slips through, the worker skips the cancelled notification.
+	messages, err := h.repo.ListOutboxMessagesByNotification(ctx, notifID)
+	if err != nil {
+		h.logger.Error("failed to list outbox messages", zap.Error(err), zap.String("notification_id", idStr))
+	}
+	for _, msg := range messages {
+		if msg.TaskType != queue.TypeFanoutNotification {
+			continue // e.g. the cancellation callback
+		}
+		if err := h.inspector.DeleteTask(msg.Queue, msg.ID.String()); err != nil {
+			h.logger.Error("failed to delete scheduled task",
+				zap.Error(err),
+				zap.String("notification_id", idStr),
+				zap.String("task_id", msg.ID.String()),
+			)
+		}
+	}
+
+	h.logger.Info("notification cancelled", zap.String("notification_id", idStr))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/notifications/:id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/notifications/:id", 204, time.Since(start).Seconds())
+}
+
 // ListDeliveryAttempts handles GET /v1/notifications/:id/attempts
 func (h *Handler) ListDeliveryAttempts(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
@@ -386,8 +753,25 @@ func (h *Handler) ListDeliveryAttempts(w http.ResponseWriter, r *http.Request) {
 			parsed, _ := uuid.FromBytes(id[:])
 			subID = &parsed
 		}
+		var contactPointID, fallbackID, topicWebhookID, templateID *uuid.UUID
+		if attempt.ContactPointID.Valid {
+			parsed := uuid.UUID(attempt.ContactPointID.Bytes)
+			contactPointID = &parsed
+		}
+		if attempt.FallbackID.Valid {
+			parsed := uuid.UUID(attempt.FallbackID.Bytes)
+			fallbackID = &parsed
+		}
+		if attempt.TopicWebhookID.Valid {
+			parsed := uuid.UUID(attempt.TopicWebhookID.Bytes)
+			topicWebhookID = &parsed
+		}
+		if attempt.TemplateID.Valid {
+			parsed := uuid.UUID(attempt.TemplateID.Bytes)
+			templateID = &parsed
+		}
 
-		var httpStatus, latencyMs, retryCount *int
+		var httpStatus, latencyMs, retryCount, templateVersion *int
 		if attempt.HttpStatus != nil {
 			status := int(*attempt.HttpStatus)
 			httpStatus = &status
@@ -400,18 +784,29 @@ func (h *Handler) ListDeliveryAttempts(w http.ResponseWriter, r *http.Request) {
 			retry := int(*attempt.RetryCount)
 			retryCount = &retry
 		}
+		if attempt.TemplateVersion != nil {
+			version := int(*attempt.TemplateVersion)
+			templateVersion = &version
+		}
 
 		respAttempts[i] = DeliveryAttemptResponse{
-			ID:             attempt.ID,
-			NotificationID: attempt.NotificationID,
-			SubscriptionID: subID,
-			UserID:         attempt.UserID,
-			Status:         attempt.Status,
-			HTTPStatus:     httpStatus,
-			LatencyMs:      latencyMs,
-			Error:          attempt.Error,
-			RetryCount:     retryCount,
-			CreatedAt:      attempt.CreatedAt,
+			ID:              attempt.ID,
+			NotificationID:  attempt.NotificationID,
+			Channel:         attempt.Channel,
+			SubscriptionID:  subID,
+			ContactPointID:  contactPointID,
+			FallbackID:      fallbackID,
+			TopicWebhookID:  topicWebhookID,
+			UserID:          attempt.UserID,
+			Status:          attempt.Status,
+			HTTPStatus:      httpStatus,
+			LatencyMs:       latencyMs,
+			Error:           attempt.Error,
+			RetryCount:      retryCount,
+			Reason:          attempt.Reason,
+			TemplateID:      templateID,
+			TemplateVersion: templateVersion,
+			CreatedAt:       attempt.CreatedAt,
 		}
 	}
 
@@ -444,43 +839,22 @@ func (h *Handler) respondError(w http.ResponseWriter, status int, message, code
 	})
 }
 
-// enqueueDeliveryTasks enqueues notification delivery tasks for all active subscriptions of the recipients
-func (h *Handler) enqueueDeliveryTasks(ctx context.Context, notificationID uuid.UUID, userIDs []string, priority string, ttl int) {
-	for _, userID := range userIDs {
-		// Get all active subscriptions for this user
-		subscriptions, err := h.repo.ListActiveDeviceSubscriptionsByUser(ctx, userID)
-		if err != nil {
-			h.logger.Error("failed to get active subscriptions for user",
-				zap.String("user_id", userID),
-				zap.String("notification_id", notificationID.String()),
-				zap.Error(err),
-			)
-			continue
-		}
-
-		// Enqueue a task for each subscription
-		for _, sub := range subscriptions {
-			if err := h.queueClient.EnqueueDeliverNotification(
-				ctx,
-				notificationID,
-				userID,
-				sub.ID,
-				priority,
-				ttl,
-			); err != nil {
-				h.logger.Error("failed to enqueue delivery task",
-					zap.String("notification_id", notificationID.String()),
-					zap.String("user_id", userID),
-					zap.String("subscription_id", sub.ID.String()),
-					zap.Error(err),
-				)
-			} else {
-				h.logger.Debug("enqueued delivery task",
-					zap.String("notification_id", notificationID.String()),
-					zap.String("user_id", userID),
-					zap.String("subscription_id", sub.ID.String()),
-				)
-			}
+// parsePagination reads limit and offset query parameters (default 50, max 200).
+func parsePagination(r *http.Request) (int32, int32, error) {
+	limit, offset := 50, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			return 0, 0, fmt.Errorf("limit must be between 1 and 200")
+		}
+		limit = n
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
 		}
+		offset = n
 	}
+	return int32(limit), int32(offset), nil
 }
@@ -0,0 +1,279 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+	"notifications/internal/templates"
+)
+
+// ListInbox handles GET /v1/users/:user_id/inbox. Items are returned newest
+// first; pass next_cursor back as cursor for the following page. unread=true
+// lists only unread items and archived=true lists the archive instead.
+func (h *Handler) ListInbox(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+	userID := chi.URLParam(r, "user_id")
+	query := r.URL.Query()
+
+	limit := 50
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 200", "VALIDATION_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/inbox", 400)
+			return
+		}
+		limit = n
+	}
+
+	params := repo.ListInboxItemsPageParams{
+		UserID:     userID,
+		Archived:   query.Get("archived") == "true",
+		UnreadOnly: query.Get("unread") == "true",
+		BatchSize:  int32(limit + 1), // One extra row tells whether there is a next page
+	}
+	if v := query.Get("cursor"); v != "" {
+		createdAt, id, err := decodeInboxCursor(v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid cursor", "INVALID_CURSOR", nil)
+			metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/inbox", 400)
+			return
+		}
+		params.BeforeCreatedAt = &createdAt
+		params.BeforeID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	var locale *string
+	if v := query.Get("locale"); v != "" {
+		locale = &v
+	}
+
+	rows, err := h.repo.ListInboxItemsPage(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list inbox", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/inbox", 500)
+		return
+	}
+
+	unread, err := h.repo.CountUnreadInboxItems(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to count unread inbox items", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/inbox", 500)
+		return
+	}
+
+	resp := ListInboxResponse{
+		UserID:      userID,
+		UnreadCount: int(unread),
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1].InboxItem
+		resp.NextCursor = encodeInboxCursor(last.CreatedAt, last.ID)
+	}
+	resp.Items = make([]InboxItemResponse, len(rows))
+	for i, row := range rows {
+		resp.Items[i] = h.toInboxItemResponse(ctx, row.InboxItem, row.Notification, locale)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/inbox", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/users/:user_id/inbox", 200, time.Since(start).Seconds())
+}
+
+// MarkInboxItemRead handles POST /v1/users/:user_id/inbox/:id/read
+func (h *Handler) MarkInboxItemRead(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid inbox item ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/read", 400)
+		return
+	}
+
+	updated, err := h.repo.MarkInboxItemRead(r.Context(), repo.MarkInboxItemReadParams{ID: id, UserID: userID})
+	if err != nil {
+		h.logger.Error("failed to mark inbox item read", zap.Error(err), zap.String("inbox_item_id", id.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to mark inbox item read", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/read", 500)
+		return
+	}
+	if updated == 0 {
+		h.respondError(w, http.StatusNotFound, "inbox item not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/read", 404)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/read", 204)
+	metrics.ObserveRequestDuration("POST", "/v1/users/:user_id/inbox/:id/read", 204, time.Since(start).Seconds())
+}
+
+// MarkAllInboxItemsRead handles POST /v1/users/:user_id/inbox/read-all
+func (h *Handler) MarkAllInboxItemsRead(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	updated, err := h.repo.MarkAllInboxItemsRead(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to mark inbox read", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to mark inbox read", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/read-all", 500)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, MarkAllInboxItemsReadResponse{UserID: userID, Updated: int(updated)})
+	metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/read-all", 200)
+	metrics.ObserveRequestDuration("POST", "/v1/users/:user_id/inbox/read-all", 200, time.Since(start).Seconds())
+}
+
+// ArchiveInboxItem handles POST /v1/users/:user_id/inbox/:id/archive.
+// Archived items leave the inbox and its unread count; list them with archived=true.
+func (h *Handler) ArchiveInboxItem(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid inbox item ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/archive", 400)
+		return
+	}
+
+	updated, err := h.repo.ArchiveInboxItem(r.Context(), repo.ArchiveInboxItemParams{ID: id, UserID: userID})
+	if err != nil {
+		h.logger.Error("failed to archive inbox item", zap.Error(err), zap.String("inbox_item_id", id.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to archive inbox item", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/archive", 500)
+		return
+	}
+	if updated == 0 {
+		h.respondError(w, http.StatusNotFound, "inbox item not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/archive", 404)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/inbox/:id/archive", 204)
+	metrics.ObserveRequestDuration("POST", "/v1/users/:user_id/inbox/:id/archive", 204, time.Since(start).Seconds())
+}
+
+// DeleteInboxItem handles DELETE /v1/users/:user_id/inbox/:id. Only the
+// recipient's copy is removed; the notification and its attempts are kept.
+func (h *Handler) DeleteInboxItem(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid inbox item ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/inbox/:id", 400)
+		return
+	}
+
+	deleted, err := h.repo.DeleteInboxItem(r.Context(), repo.DeleteInboxItemParams{ID: id, UserID: userID})
+	if err != nil {
+		h.logger.Error("failed to delete inbox item", zap.Error(err), zap.String("inbox_item_id", id.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete inbox item", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/inbox/:id", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "inbox item not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/inbox/:id", 404)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/inbox/:id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/users/:user_id/inbox/:id", 204, time.Since(start).Seconds())
+}
+
+// toInboxItemResponse renders an inbox item's copy the way push renders it:
+// the notification's template in the requested, notification or default
+// locale, with explicit fields taking priority.
+func (h *Handler) toInboxItemResponse(ctx context.Context, item repo.InboxItem, notif repo.Notification, locale *string) InboxItemResponse {
+	var data map[string]interface{}
+	if len(notif.Data) > 0 {
+		_ = json.Unmarshal(notif.Data, &data)
+	}
+
+	content, err := h.resolver.ResolveNotification(ctx, notif, data, locale, notif.Locale)
+	var missing *templates.MissingVariablesError
+	if err != nil && !errors.As(err, &missing) && !errors.Is(err, templates.ErrTemplateNotFound) {
+		h.logger.Warn("failed to render inbox item", zap.Error(err), zap.String("notification_id", notif.ID.String()))
+	}
+	if content == nil {
+		content = &templates.Content{Locale: h.resolver.ChooseLocale(locale, notif.Locale)}
+	}
+
+	return InboxItemResponse{
+		ID:             item.ID,
+		NotificationID: notif.ID,
+		Type:           notif.Type,
+		Locale:         content.Locale,
+		Title:          explicitOr(notif.Title, content.Title),
+		Body:           explicitOr(notif.Body, content.Body),
+		Icon:           explicitOr(notif.Icon, content.Icon),
+		URL:            explicitOr(notif.Url, content.URL),
+		Data:           notif.Data,
+		Read:           item.ReadAt != nil,
+		ReadAt:         item.ReadAt,
+		ArchivedAt:     item.ArchivedAt,
+		CreatedAt:      item.CreatedAt,
+	}
+}
+
+// explicitOr returns the explicit value if set, otherwise the rendered one.
+func explicitOr(explicit *string, rendered string) string {
+	if explicit != nil && *explicit != "" {
+		return *explicit
+	}
+	return rendered
+}
+
+// encodeInboxCursor returns an opaque cursor pointing after an inbox item
+func encodeInboxCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeInboxCursor parses a cursor returned by encodeInboxCursor
+func decodeInboxCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, err
+	}
+	ts, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, err
+	}
+	return createdAt, id, nil
+}
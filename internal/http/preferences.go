@@ -0,0 +1,100 @@
+package apihttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// GetPreferences handles GET /v1/users/:user_id/preferences
+func (h *Handler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	prefs, err := h.repo.ListPreferencesByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list preferences", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/preferences", 500)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, toPreferencesResponse(userID, prefs))
+	metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/preferences", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/users/:user_id/preferences", 200, time.Since(start).Seconds())
+}
+
+// PutPreferences handles PUT /v1/users/:user_id/preferences. The request
+// replaces every preference of the user; an empty list opts back in to all types.
+func (h *Handler) PutPreferences(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+	userID := chi.URLParam(r, "user_id")
+
+	if len(userID) > 255 {
+		h.respondError(w, http.StatusBadRequest, "user_id exceeds 255 characters", "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/preferences", 400)
+		return
+	}
+
+	var req PreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/preferences", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/preferences", 400)
+		return
+	}
+
+	prefs := make([]repo.UserNotificationPreference, 0, len(req.Preferences))
+	err := h.repo.WithTx(ctx, func(q *repo.Queries) error {
+		if err := q.DeletePreferencesByUser(ctx, userID); err != nil {
+			return err
+		}
+		for _, p := range req.Preferences {
+			pref, err := q.CreatePreference(ctx, repo.CreatePreferenceParams{
+				UserID:      userID,
+				TypePattern: p.Type,
+				Enabled:     p.Enabled,
+			})
+			if err != nil {
+				return err
+			}
+			prefs = append(prefs, pref)
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("failed to save preferences", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to save preferences", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/preferences", 500)
+		return
+	}
+
+	h.logger.Info("preferences saved", zap.String("user_id", userID), zap.Int("count", len(prefs)))
+
+	h.respondJSON(w, http.StatusOK, toPreferencesResponse(userID, prefs))
+	metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/preferences", 200)
+	metrics.ObserveRequestDuration("PUT", "/v1/users/:user_id/preferences", 200, time.Since(start).Seconds())
+}
+
+// toPreferencesResponse converts preference rows to their API representation.
+func toPreferencesResponse(userID string, prefs []repo.UserNotificationPreference) PreferencesResponse {
+	resp := PreferencesResponse{
+		UserID:      userID,
+		Preferences: make([]Preference, len(prefs)),
+	}
+	for i, p := range prefs {
+		resp.Preferences[i] = Preference{Type: p.TypePattern, Enabled: p.Enabled}
+	}
+	return resp
+}
@@ -0,0 +1,125 @@
+package apihttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// GetQuietHours handles GET /v1/users/:user_id/quiet-hours
+func (h *Handler) GetQuietHours(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	qh, err := h.repo.GetQuietHours(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.respondError(w, http.StatusNotFound, "quiet hours not set", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/quiet-hours", 404)
+			return
+		}
+		h.logger.Error("failed to get quiet hours", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/quiet-hours", 500)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, toQuietHoursResponse(qh))
+	metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/quiet-hours", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/users/:user_id/quiet-hours", 200, time.Since(start).Seconds())
+}
+
+// PutQuietHours handles PUT /v1/users/:user_id/quiet-hours
+func (h *Handler) PutQuietHours(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	if len(userID) > 255 {
+		h.respondError(w, http.StatusBadRequest, "user_id exceeds 255 characters", "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/quiet-hours", 400)
+		return
+	}
+
+	var req QuietHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/quiet-hours", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/quiet-hours", 400)
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	qh, err := h.repo.UpsertQuietHours(r.Context(), repo.UpsertQuietHoursParams{
+		UserID:    userID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Timezone:  req.Timezone,
+		Enabled:   enabled,
+	})
+	if err != nil {
+		h.logger.Error("failed to save quiet hours", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to save quiet hours", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/quiet-hours", 500)
+		return
+	}
+
+	h.logger.Info("quiet hours saved", zap.String("user_id", userID))
+
+	h.respondJSON(w, http.StatusOK, toQuietHoursResponse(qh))
+	metrics.IncHTTPRequestsTotal("PUT", "/v1/users/:user_id/quiet-hours", 200)
+	metrics.ObserveRequestDuration("PUT", "/v1/users/:user_id/quiet-hours", 200, time.Since(start).Seconds())
+}
+
+// DeleteQuietHours handles DELETE /v1/users/:user_id/quiet-hours
+func (h *Handler) DeleteQuietHours(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	deleted, err := h.repo.DeleteQuietHours(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to delete quiet hours", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete quiet hours", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/quiet-hours", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "quiet hours not set", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/quiet-hours", 404)
+		return
+	}
+
+	h.logger.Info("quiet hours deleted", zap.String("user_id", userID))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/users/:user_id/quiet-hours", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/users/:user_id/quiet-hours", 204, time.Since(start).Seconds())
+}
+
+// toQuietHoursResponse converts a quiet-hours row to its API representation.
+func toQuietHoursResponse(qh repo.UserQuietHour) QuietHoursResponse {
+	return QuietHoursResponse{
+		UserID:    qh.UserID,
+		StartTime: qh.StartTime,
+		EndTime:   qh.EndTime,
+		Timezone:  qh.Timezone,
+		Enabled:   qh.Enabled,
+		CreatedAt: qh.CreatedAt,
+		UpdatedAt: qh.UpdatedAt,
+	}
+}
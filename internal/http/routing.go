@@ -0,0 +1,117 @@
+package apihttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// ListRoutingPolicies handles GET /v1/routing-policies
+func (h *Handler) ListRoutingPolicies(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	policies, err := h.repo.ListRoutingPolicies(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list routing policies", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/routing-policies", 500)
+		return
+	}
+
+	resp := ListRoutingPoliciesResponse{Policies: make([]RoutingPolicyResponse, len(policies))}
+	for i, policy := range policies {
+		resp.Policies[i] = toRoutingPolicyResponse(policy)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/routing-policies", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/routing-policies", 200, time.Since(start).Seconds())
+}
+
+// PutRoutingPolicy handles PUT /v1/routing-policies/:type. The policy applies
+// to notifications fanned out after it is saved.
+func (h *Handler) PutRoutingPolicy(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	notificationType := chi.URLParam(r, "type")
+
+	if len(notificationType) > 50 {
+		h.respondError(w, http.StatusBadRequest, "type exceeds 50 characters", "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/routing-policies/:type", 400)
+		return
+	}
+
+	var req RoutingPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/routing-policies/:type", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/routing-policies/:type", 400)
+		return
+	}
+
+	policy, err := h.repo.UpsertRoutingPolicy(r.Context(), repo.UpsertRoutingPolicyParams{
+		Type:                 notificationType,
+		FallbackChannel:      req.FallbackChannel,
+		FallbackDelaySeconds: int32(req.FallbackDelaySeconds),
+	})
+	if err != nil {
+		h.logger.Error("failed to save routing policy", zap.Error(err), zap.String("type", notificationType))
+		h.respondError(w, http.StatusInternalServerError, "failed to save routing policy", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/routing-policies/:type", 500)
+		return
+	}
+
+	h.logger.Info("routing policy saved",
+		zap.String("type", notificationType),
+		zap.String("fallback_channel", policy.FallbackChannel),
+	)
+
+	h.respondJSON(w, http.StatusOK, toRoutingPolicyResponse(policy))
+	metrics.IncHTTPRequestsTotal("PUT", "/v1/routing-policies/:type", 200)
+	metrics.ObserveRequestDuration("PUT", "/v1/routing-policies/:type", 200, time.Since(start).Seconds())
+}
+
+// DeleteRoutingPolicy handles DELETE /v1/routing-policies/:type
+func (h *Handler) DeleteRoutingPolicy(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	notificationType := chi.URLParam(r, "type")
+
+	deleted, err := h.repo.DeleteRoutingPolicy(r.Context(), notificationType)
+	if err != nil {
+		h.logger.Error("failed to delete routing policy", zap.Error(err), zap.String("type", notificationType))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete routing policy", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/routing-policies/:type", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "routing policy not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/routing-policies/:type", 404)
+		return
+	}
+
+	h.logger.Info("routing policy deleted", zap.String("type", notificationType))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/routing-policies/:type", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/routing-policies/:type", 204, time.Since(start).Seconds())
+}
+
+// toRoutingPolicyResponse converts a routing policy row to its API representation.
+func toRoutingPolicyResponse(policy repo.NotificationRoutingPolicy) RoutingPolicyResponse {
+	return RoutingPolicyResponse{
+		Type:                 policy.Type,
+		FallbackChannel:      policy.FallbackChannel,
+		FallbackDelaySeconds: int(policy.FallbackDelaySeconds),
+		CreatedAt:            policy.CreatedAt,
+		UpdatedAt:            policy.UpdatedAt,
+	}
+}
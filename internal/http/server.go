@@ -15,10 +15,13 @@ import (
 	"notifications/internal/middleware"
 	"notifications/internal/queue"
 	"notifications/internal/repo"
+	"notifications/internal/stream"
+	"notifications/internal/templates"
+	"notifications/internal/tracking"
 )
 
 // NewRouter wires routes and middleware.
-func NewRouter(cfg config.Config, r *repo.Repository, queueClient *queue.Client, logger *zap.Logger) http.Handler {
+func NewRouter(cfg config.Config, r *repo.Repository, queueClient *queue.Client, inspector *queue.Inspector, hub *stream.Hub, secretCipher *auth.SecretCipher, logger *zap.Logger) http.Handler {
 	mux := chi.NewRouter()
 
 	// Global middleware
@@ -31,19 +34,120 @@ func NewRouter(cfg config.Config, r *repo.Repository, queueClient *queue.Client,
 	mux.Get("/metrics", promhttp.Handler().ServeHTTP)
 	mux.Get("/v1/push/public-key", vapidPublicKeyHandler(cfg))
 
-	// Protected routes (require HMAC auth)
-	h := NewHandler(r, queueClient, logger)
-	mux.Group(func(protected chi.Router) {
-		protected.Use(auth.VerifyHMACMiddleware([]byte(cfg.HMACSecret), 5*time.Minute))
+	var tracker *tracking.Signer
+	if cfg.TrackingBaseURL != "" {
+		tracker = tracking.NewSigner(cfg.TrackingSecret, cfg.TrackingBaseURL, cfg.AppBaseURL)
+	}
+	var streamTokens *stream.TokenSigner
+	if cfg.StreamTokenSecret != "" {
+		streamTokens = stream.NewTokenSigner(cfg.StreamTokenSecret, cfg.StreamTokenTTL)
+	}
+	h := NewHandler(r, queueClient, inspector, templates.NewResolver(cfg.DefaultLocale, r), hub, tracker, streamTokens, secretCipher, cfg.CORSAllowedOrigins, cfg.IdempotencyRetention, cfg.DefaultCallbackURL, logger)
+
+	// Tracking routes are public; signed tokens identify the recipient
+	mux.Get("/v1/t/{token}", h.TrackClick)
+	mux.Post("/v1/events", h.ReportEvent)
 
-		// Subscriptions
-		protected.Post("/v1/subscriptions", h.RegisterSubscription)
-		protected.Delete("/v1/subscriptions/{id}", h.UnregisterSubscription)
+	// Inbox streams are public; a stream token minted by a client opens one
+	// user's stream
+	mux.Get("/v1/users/{user_id}/stream", h.StreamInbox)
+	mux.Get("/v1/users/{user_id}/ws", h.InboxSocket)
+
+	// Protected routes (require a signed request from an API client)
+	var sharedSecret []byte
+	if cfg.SharedSecretAuth {
+		sharedSecret = []byte(cfg.HMACSecret)
+	}
+	mux.Group(func(protected chi.Router) {
+		protected.Use(auth.VerifyClientMiddleware(apiClientStore{repo: r, cipher: secretCipher}, sharedSecret, 5*time.Minute))
 
 		// Notifications
-		protected.Post("/v1/notifications", h.SendNotification)
-		protected.Get("/v1/notifications/{id}", h.GetNotification)
-		protected.Get("/v1/notifications/{id}/attempts", h.ListDeliveryAttempts)
+		protected.With(auth.RequireScope(auth.ScopeNotificationsWrite)).Post("/v1/notifications", h.SendNotification)
+		protected.With(auth.RequireScope(auth.ScopeNotificationsWrite)).Delete("/v1/notifications/{id}", h.CancelNotification)
+		protected.Group(func(read chi.Router) {
+			read.Use(auth.RequireScope(auth.ScopeNotificationsRead))
+			read.Get("/v1/notifications/{id}", h.GetNotification)
+			read.Get("/v1/notifications/{id}/attempts", h.ListDeliveryAttempts)
+
+			// Analytics
+			read.Get("/v1/analytics/engagement", h.GetEngagementByType)
+		})
+
+		protected.Group(func(users chi.Router) {
+			users.Use(auth.RequireScope(auth.ScopeUsers))
+
+			// Subscriptions
+			users.Post("/v1/subscriptions", h.RegisterSubscription)
+			users.Delete("/v1/subscriptions/{id}", h.UnregisterSubscription)
+
+			// User settings
+			users.Get("/v1/users/{user_id}/quiet-hours", h.GetQuietHours)
+			users.Put("/v1/users/{user_id}/quiet-hours", h.PutQuietHours)
+			users.Delete("/v1/users/{user_id}/quiet-hours", h.DeleteQuietHours)
+			users.Get("/v1/users/{user_id}/preferences", h.GetPreferences)
+			users.Put("/v1/users/{user_id}/preferences", h.PutPreferences)
+			users.Get("/v1/users/{user_id}/contact-points", h.ListContactPoints)
+			users.Post("/v1/users/{user_id}/contact-points", h.CreateContactPoint)
+			users.Delete("/v1/users/{user_id}/contact-points/{id}", h.DeleteContactPoint)
+			users.Get("/v1/users/{user_id}/inbox", h.ListInbox)
+			users.Post("/v1/users/{user_id}/inbox/read-all", h.MarkAllInboxItemsRead)
+			users.Post("/v1/users/{user_id}/inbox/{id}/read", h.MarkInboxItemRead)
+			users.Post("/v1/users/{user_id}/inbox/{id}/archive", h.ArchiveInboxItem)
+			users.Delete("/v1/users/{user_id}/inbox/{id}", h.DeleteInboxItem)
+			users.Post("/v1/users/{user_id}/stream-token", h.IssueStreamToken)
+
+			// Topic subscribers
+			users.Get("/v1/topics/{name}/subscribers", h.ListTopicSubscribers)
+			users.Put("/v1/topics/{name}/subscribers/{user_id}", h.SubscribeToTopic)
+			users.Delete("/v1/topics/{name}/subscribers/{user_id}", h.UnsubscribeFromTopic)
+		})
+
+		protected.Group(func(settings chi.Router) {
+			settings.Use(auth.RequireScope(auth.ScopeConfig))
+
+			// Topics
+			settings.Post("/v1/topics", h.CreateTopic)
+			settings.Get("/v1/topics", h.ListTopics)
+			settings.Get("/v1/topics/{name}", h.GetTopic)
+			settings.Delete("/v1/topics/{name}", h.DeleteTopic)
+			settings.Get("/v1/topics/{name}/webhooks", h.ListTopicWebhooks)
+			settings.Post("/v1/topics/{name}/webhooks", h.CreateTopicWebhook)
+			settings.Delete("/v1/topics/{name}/webhooks/{id}", h.DeleteTopicWebhook)
+
+			// Routing policies
+			settings.Get("/v1/routing-policies", h.ListRoutingPolicies)
+			settings.Put("/v1/routing-policies/{type}", h.PutRoutingPolicy)
+			settings.Delete("/v1/routing-policies/{type}", h.DeleteRoutingPolicy)
+
+			// Templates
+			settings.Post("/v1/templates", h.CreateTemplate)
+			settings.Get("/v1/templates", h.ListTemplates)
+			settings.Post("/v1/templates/preview", h.PreviewTemplate)
+			settings.Get("/v1/templates/{id}", h.GetTemplate)
+			settings.Put("/v1/templates/{id}", h.UpdateTemplate)
+			settings.Post("/v1/templates/{id}/activate", h.ActivateTemplate)
+			settings.Delete("/v1/templates/{id}", h.DeleteTemplate)
+		})
+
+		protected.Group(func(admin chi.Router) {
+			admin.Use(auth.RequireScope(auth.ScopeAdmin))
+
+			// Dead-letter queue
+			admin.Get("/v1/admin/dlq", h.ListDeadLetters)
+			admin.Post("/v1/admin/dlq/requeue", h.RequeueDeadLetters)
+			admin.Post("/v1/admin/dlq/purge", h.PurgeDeadLetters)
+			admin.Get("/v1/admin/dlq/{queue}/{task_id}", h.GetDeadLetter)
+			admin.Post("/v1/admin/dlq/{queue}/{task_id}/requeue", h.RequeueDeadLetter)
+			admin.Delete("/v1/admin/dlq/{queue}/{task_id}", h.PurgeDeadLetter)
+
+			// API clients
+			admin.Post("/v1/admin/api-clients", h.CreateAPIClient)
+			admin.Get("/v1/admin/api-clients", h.ListAPIClients)
+			admin.Get("/v1/admin/api-clients/{key_id}", h.GetAPIClient)
+			admin.Put("/v1/admin/api-clients/{key_id}", h.UpdateAPIClient)
+			admin.Post("/v1/admin/api-clients/{key_id}/secrets", h.CreateAPIClientSecret)
+			admin.Delete("/v1/admin/api-clients/{key_id}/secrets/{id}", h.DeleteAPIClientSecret)
+		})
 	})
 
 	return mux
@@ -106,8 +210,8 @@ func corsMiddleware(allowed []string) func(http.Handler) http.Handler {
 			if originAllowed(origin, allowed) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Vary", "Origin")
-				w.Header().Set("Access-Control-Allow-Methods", "GET,POST,DELETE,OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,X-Signature,X-Timestamp")
+				w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,X-Key-ID,X-Signature,X-Timestamp")
 			}
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
@@ -0,0 +1,224 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// WebSocket message types
+const (
+	socketMessageNotification = "notification"
+	socketMessageAck          = "ack"
+	socketMessageError        = "error"
+)
+
+// socketReadLimit caps client messages, which are only ever small acks
+const socketReadLimit = 4096
+
+// socketWriteTimeout bounds each write so a stalled client is dropped
+// instead of holding the socket open
+const socketWriteTimeout = 10 * time.Second
+
+// InboxSocket handles GET /v1/users/:user_id/ws?token=, a WebSocket carrying
+// the same inbox events as StreamInbox, opened with the same stream tokens and
+// resumable with last_event_id. Clients send
+// {"type":"ack","event":"delivered|displayed|clicked","notification_id":...}
+// back over the socket; each ack is recorded as a notification event and
+// answered with an ack or error message.
+func (h *Handler) InboxSocket(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authorizeStream(w, r, "/v1/users/:user_id/ws")
+	if !ok {
+		return
+	}
+
+	var lastCreatedAt time.Time
+	var lastID uuid.UUID
+	lastEventID := r.URL.Query().Get("last_event_id")
+	resume := lastEventID != ""
+	if resume {
+		var err error
+		lastCreatedAt, lastID, err = decodeInboxCursor(lastEventID)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid last_event_id", "INVALID_CURSOR", nil)
+			metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/ws", 400)
+			return
+		}
+	}
+
+	// Hijacked connections keep the server's deadlines unless cleared
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn("failed to clear socket read deadline", zap.Error(err))
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn("failed to clear socket write deadline", zap.Error(err))
+	}
+
+	// Browsers may connect from the API's own origin or a CORS-allowed one
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.socketOrigins})
+	if err != nil {
+		h.logger.Warn("failed to accept websocket", zap.Error(err), zap.String("user_id", userID))
+		metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/ws", 400)
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(socketReadLimit)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/ws", 101)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Subscribe before replaying so nothing created in between is missed;
+	// events already replayed are skipped by cursor
+	events, unsubscribe := h.hub.Subscribe(userID)
+	defer unsubscribe()
+
+	// Acks are read on their own goroutine; Conn allows concurrent writes
+	go func() {
+		defer cancel()
+		h.readSocketAcks(ctx, conn, userID)
+	}()
+
+	send := func(item repo.InboxItem, notif repo.Notification) error {
+		return h.writeSocket(ctx, conn, SocketNotification{
+			Type: socketMessageNotification,
+			ID:   encodeInboxCursor(item.CreatedAt, item.ID),
+			Item: h.toInboxItemResponse(ctx, item, notif, nil),
+		})
+	}
+
+	if resume {
+		if lastCreatedAt, lastID, err = h.replayInbox(ctx, userID, lastCreatedAt, lastID, send); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-heartbeat.C:
+			pingCtx, cancelPing := context.WithTimeout(ctx, socketWriteTimeout)
+			err := conn.Ping(pingCtx)
+			cancelPing()
+			if err != nil {
+				return
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				// Fell too far behind; the client reconnects and resumes
+				conn.Close(websocket.StatusTryAgainLater, "subscriber fell behind")
+				return
+			}
+			if resume && !afterCursor(event.CreatedAt, event.InboxItemID, lastCreatedAt, lastID) {
+				continue // Already replayed
+			}
+			row, err := h.repo.GetInboxItem(ctx, repo.GetInboxItemParams{ID: event.InboxItemID, UserID: userID})
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue // Deleted since
+			}
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					h.logger.Error("failed to load inbox item", zap.Error(err), zap.String("inbox_item_id", event.InboxItemID.String()))
+				}
+				conn.Close(websocket.StatusInternalError, "internal server error")
+				return
+			}
+			if err := send(row.InboxItem, row.Notification); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readSocketAcks records the acks a client sends until the socket closes.
+// Invalid messages are answered with an error message rather than closing
+// the socket.
+func (h *Handler) readSocketAcks(ctx context.Context, conn *websocket.Conn, userID string) {
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var msg SocketMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if h.writeSocketError(ctx, conn, "invalid JSON", "INVALID_JSON") != nil {
+				return
+			}
+			continue
+		}
+		if err := msg.Validate(); err != nil {
+			if h.writeSocketError(ctx, conn, err.Error(), "VALIDATION_ERROR") != nil {
+				return
+			}
+			continue
+		}
+
+		notifID := uuid.MustParse(msg.NotificationID)
+		recorded, err := h.recordNotificationEvent(ctx, notifID, userID, msg.Event, eventSourceWebSocket)
+		if errors.Is(err, errNotRecipient) {
+			if h.writeSocketError(ctx, conn, "notification not found", "NOT_FOUND") != nil {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			h.logger.Error("failed to record notification event", zap.Error(err),
+				zap.String("notification_id", msg.NotificationID), zap.String("user_id", userID))
+			if h.writeSocketError(ctx, conn, "internal server error", "INTERNAL_ERROR") != nil {
+				return
+			}
+			continue
+		}
+
+		ack := SocketAck{
+			Type:           socketMessageAck,
+			NotificationID: notifID,
+			Event:          msg.Event,
+			Recorded:       recorded,
+		}
+		if h.writeSocket(ctx, conn, ack) != nil {
+			return
+		}
+	}
+}
+
+// writeSocket sends v as a JSON text message
+func (h *Handler) writeSocket(ctx context.Context, conn *websocket.Conn, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, socketWriteTimeout)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// writeSocketError sends an error message for a rejected client message
+func (h *Handler) writeSocketError(ctx context.Context, conn *websocket.Conn, msg, code string) error {
+	return h.writeSocket(ctx, conn, SocketError{
+		Type:  socketMessageError,
+		Error: msg,
+		Code:  code,
+	})
+}
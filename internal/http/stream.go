@@ -0,0 +1,223 @@
+package apihttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+)
+
+// streamHeartbeat keeps idle streams open through proxies that time out silent connections
+const streamHeartbeat = 25 * time.Second
+
+// streamReplayBatch is how many missed inbox items a resumed stream loads per query
+const streamReplayBatch = 100
+
+// IssueStreamToken handles POST /v1/users/:user_id/stream-token. Browsers
+// can't sign requests, so the app's backend mints a short-lived token for the
+// signed-in user and the browser opens its stream or WebSocket with ?token=.
+func (h *Handler) IssueStreamToken(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	userID := chi.URLParam(r, "user_id")
+
+	if h.streamTokens == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "streams not configured", "NOT_CONFIGURED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/stream-token", 503)
+		return
+	}
+	token, expiresAt, err := h.streamTokens.Issue(userID, start)
+	if err != nil {
+		h.logger.Error("failed to issue stream token", zap.Error(err), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to issue stream token", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/stream-token", 500)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, StreamTokenResponse{UserID: userID, Token: token, ExpiresAt: expiresAt})
+	metrics.IncHTTPRequestsTotal("POST", "/v1/users/:user_id/stream-token", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/users/:user_id/stream-token", 201, time.Since(start).Seconds())
+}
+
+// authorizeStream checks the request's stream token against the path's
+// user_id, responding with an error if it doesn't open that user's stream
+func (h *Handler) authorizeStream(w http.ResponseWriter, r *http.Request, route string) (string, bool) {
+	userID := chi.URLParam(r, "user_id")
+	if h.streamTokens == nil {
+		h.respondError(w, http.StatusNotFound, "stream not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("GET", route, 404)
+		return "", false
+	}
+	tokenUserID, err := h.streamTokens.Verify(r.URL.Query().Get("token"), time.Now())
+	if err != nil || tokenUserID != userID {
+		h.respondError(w, http.StatusUnauthorized, "invalid stream token", "INVALID_TOKEN", nil)
+		metrics.IncHTTPRequestsTotal("GET", route, 401)
+		return "", false
+	}
+	return userID, true
+}
+
+// StreamInbox handles GET /v1/users/:user_id/stream?token=, a Server-Sent
+// Events stream of new inbox items. Each event's ID is an inbox cursor: a
+// client reconnecting with Last-Event-ID (or last_event_id, for the first
+// connection) first receives the unarchived items created after it, then live
+// events.
+func (h *Handler) StreamInbox(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := h.authorizeStream(w, r, "/v1/users/:user_id/stream")
+	if !ok {
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastCreatedAt time.Time
+	var lastID uuid.UUID
+	resume := lastEventID != ""
+	if resume {
+		var err error
+		lastCreatedAt, lastID, err = decodeInboxCursor(lastEventID)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid Last-Event-ID", "INVALID_CURSOR", nil)
+			metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/stream", 400)
+			return
+		}
+	}
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn("failed to clear stream write deadline", zap.Error(err))
+	}
+
+	// Subscribe before replaying so nothing created in between is missed;
+	// events already replayed are skipped by cursor
+	events, unsubscribe := h.hub.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		h.logger.Error("streaming not supported", zap.Error(err))
+		return
+	}
+	metrics.IncHTTPRequestsTotal("GET", "/v1/users/:user_id/stream", 200)
+
+	send := func(item repo.InboxItem, notif repo.Notification) error {
+		data, err := json.Marshal(h.toInboxItemResponse(ctx, item, notif, nil))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: notification\ndata: %s\n\n", encodeInboxCursor(item.CreatedAt, item.ID), data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if resume {
+		var err error
+		if lastCreatedAt, lastID, err = h.replayInbox(ctx, userID, lastCreatedAt, lastID, send); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				// Fell too far behind; the client reconnects and resumes
+				return
+			}
+			if resume && !afterCursor(event.CreatedAt, event.InboxItemID, lastCreatedAt, lastID) {
+				continue // Already replayed
+			}
+			row, err := h.repo.GetInboxItem(ctx, repo.GetInboxItemParams{ID: event.InboxItemID, UserID: userID})
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue // Deleted since
+			}
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					h.logger.Error("failed to load inbox item", zap.Error(err), zap.String("inbox_item_id", event.InboxItemID.String()))
+				}
+				return
+			}
+			if err := send(row.InboxItem, row.Notification); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// replayInbox sends the user's unarchived inbox items created after the
+// cursor, oldest first, and returns the cursor of the last one sent. Load
+// errors are logged; send errors are returned as is.
+func (h *Handler) replayInbox(
+	ctx context.Context,
+	userID string,
+	lastCreatedAt time.Time,
+	lastID uuid.UUID,
+	send func(repo.InboxItem, repo.Notification) error,
+) (time.Time, uuid.UUID, error) {
+	for {
+		rows, err := h.repo.ListInboxItemsAfter(ctx, repo.ListInboxItemsAfterParams{
+			UserID:         userID,
+			AfterCreatedAt: lastCreatedAt,
+			AfterID:        lastID,
+			BatchSize:      streamReplayBatch,
+		})
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				h.logger.Error("failed to replay inbox", zap.Error(err), zap.String("user_id", userID))
+			}
+			return lastCreatedAt, lastID, err
+		}
+		for _, row := range rows {
+			if err := send(row.InboxItem, row.Notification); err != nil {
+				return lastCreatedAt, lastID, err
+			}
+			lastCreatedAt, lastID = row.InboxItem.CreatedAt, row.InboxItem.ID
+		}
+		if len(rows) < streamReplayBatch {
+			return lastCreatedAt, lastID, nil
+		}
+	}
+}
+
+// afterCursor reports whether an inbox item sorts after the cursor position,
+// in the (created_at, id) order the inbox is listed in
+func afterCursor(createdAt time.Time, id uuid.UUID, cursorCreatedAt time.Time, cursorID uuid.UUID) bool {
+	if !createdAt.Equal(cursorCreatedAt) {
+		return createdAt.After(cursorCreatedAt)
+	}
+	return bytes.Compare(id[:], cursorID[:]) > 0
+}
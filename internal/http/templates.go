@@ -0,0 +1,439 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+	"notifications/internal/templates"
+	"notifications/internal/webpush"
+)
+
+// CreateTemplate handles POST /v1/templates
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	var req CreateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to decode create template request", zap.Error(err))
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/templates", 400)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/templates", 400)
+		return
+	}
+
+	tmpl, err := h.createTemplateVersion(ctx, repo.CreateTemplateParams{
+		Type:     req.Type,
+		Locale:   templates.NormalizeLocale(req.Locale),
+		Title:    req.Title,
+		Body:     req.Body,
+		Url:      req.URL,
+		Icon:     req.Icon,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		h.respondTemplateWriteError(w, err, "POST", "/v1/templates")
+		return
+	}
+
+	h.logger.Info("template version created",
+		zap.String("template_id", tmpl.ID.String()),
+		zap.String("type", tmpl.Type),
+		zap.String("locale", tmpl.Locale),
+		zap.Int32("version", tmpl.Version),
+	)
+
+	h.respondJSON(w, http.StatusCreated, toTemplateResponse(tmpl))
+	metrics.IncHTTPRequestsTotal("POST", "/v1/templates", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/templates", 201, time.Since(start).Seconds())
+}
+
+// ListTemplates handles GET /v1/templates
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/templates", 400)
+		return
+	}
+
+	params := repo.ListTemplatesParams{Limit: limit, Offset: offset}
+	if t := r.URL.Query().Get("type"); t != "" {
+		params.Type = &t
+	}
+	if l := r.URL.Query().Get("locale"); l != "" {
+		locale := templates.NormalizeLocale(l)
+		params.Locale = &locale
+	}
+
+	list, err := h.repo.ListTemplates(ctx, params)
+	if err != nil {
+		h.logger.Error("failed to list templates", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/templates", 500)
+		return
+	}
+
+	resp := ListTemplatesResponse{
+		Templates: make([]TemplateResponse, len(list)),
+		Total:     len(list),
+	}
+	for i, tmpl := range list {
+		resp.Templates[i] = toTemplateResponse(tmpl)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/templates", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/templates", 200, time.Since(start).Seconds())
+}
+
+// GetTemplate handles GET /v1/templates/:id
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	tmpl, ok := h.loadTemplate(w, r, "GET", "/v1/templates/:id")
+	if !ok {
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, toTemplateResponse(tmpl))
+	metrics.IncHTTPRequestsTotal("GET", "/v1/templates/:id", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/templates/:id", 200, time.Since(start).Seconds())
+}
+
+// UpdateTemplate handles PUT /v1/templates/:id by creating a new version
+// with the given changes applied on top of the referenced version.
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	base, ok := h.loadTemplate(w, r, "PUT", "/v1/templates/:id")
+	if !ok {
+		return
+	}
+
+	var req UpdateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/templates/:id", 400)
+		return
+	}
+
+	params := repo.CreateTemplateParams{
+		Type:     base.Type,
+		Locale:   base.Locale,
+		Title:    base.Title,
+		Body:     base.Body,
+		Url:      base.Url,
+		Icon:     base.Icon,
+		IsActive: base.IsActive,
+	}
+	if req.Title != nil {
+		params.Title = *req.Title
+	}
+	if req.Body != nil {
+		params.Body = *req.Body
+	}
+	if req.URL != nil {
+		params.Url = req.URL
+	}
+	if req.Icon != nil {
+		params.Icon = req.Icon
+	}
+	if req.IsActive != nil {
+		params.IsActive = *req.IsActive
+	}
+
+	if err := validateTemplatePatterns(params.Title, params.Body, params.Url, params.Icon); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/templates/:id", 400)
+		return
+	}
+
+	tmpl, err := h.createTemplateVersion(ctx, params)
+	if err != nil {
+		h.respondTemplateWriteError(w, err, "PUT", "/v1/templates/:id")
+		return
+	}
+
+	h.logger.Info("template version created from update",
+		zap.String("template_id", tmpl.ID.String()),
+		zap.String("previous_template_id", base.ID.String()),
+		zap.Int32("version", tmpl.Version),
+	)
+
+	h.respondJSON(w, http.StatusCreated, toTemplateResponse(tmpl))
+	metrics.IncHTTPRequestsTotal("PUT", "/v1/templates/:id", 201)
+	metrics.ObserveRequestDuration("PUT", "/v1/templates/:id", 201, time.Since(start).Seconds())
+}
+
+// ActivateTemplate handles POST /v1/templates/:id/activate, making this
+// version the active one for its type and locale (e.g. to roll back).
+func (h *Handler) ActivateTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	base, ok := h.loadTemplate(w, r, "POST", "/v1/templates/:id/activate")
+	if !ok {
+		return
+	}
+
+	var tmpl repo.NotificationTemplate
+	err := h.repo.WithTx(ctx, func(q *repo.Queries) error {
+		if err := q.DeactivateTemplates(ctx, repo.DeactivateTemplatesParams{
+			Type:   base.Type,
+			Locale: base.Locale,
+		}); err != nil {
+			return err
+		}
+		var err error
+		tmpl, err = q.SetTemplateActive(ctx, repo.SetTemplateActiveParams{ID: base.ID, IsActive: true})
+		return err
+	})
+	if err != nil {
+		h.respondTemplateWriteError(w, err, "POST", "/v1/templates/:id/activate")
+		return
+	}
+
+	h.logger.Info("template version activated",
+		zap.String("template_id", tmpl.ID.String()),
+		zap.Int32("version", tmpl.Version),
+	)
+
+	h.respondJSON(w, http.StatusOK, toTemplateResponse(tmpl))
+	metrics.IncHTTPRequestsTotal("POST", "/v1/templates/:id/activate", 200)
+	metrics.ObserveRequestDuration("POST", "/v1/templates/:id/activate", 200, time.Since(start).Seconds())
+}
+
+// DeleteTemplate handles DELETE /v1/templates/:id. Versions referenced by
+// notifications cannot be deleted so their rendered copy stays auditable.
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	tmpl, ok := h.loadTemplate(w, r, "DELETE", "/v1/templates/:id")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteTemplate(ctx, tmpl.ID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			h.respondError(w, http.StatusConflict, "template version is referenced by notifications; deactivate it instead", "TEMPLATE_IN_USE", nil)
+			metrics.IncHTTPRequestsTotal("DELETE", "/v1/templates/:id", 409)
+			return
+		}
+		h.logger.Error("failed to delete template", zap.Error(err), zap.String("template_id", tmpl.ID.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete template", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/templates/:id", 500)
+		return
+	}
+
+	h.logger.Info("template version deleted", zap.String("template_id", tmpl.ID.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/templates/:id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/templates/:id", 204, time.Since(start).Seconds())
+}
+
+// PreviewTemplate handles POST /v1/templates/preview. It renders the exact
+// Web Push payload the worker would send, without creating a notification.
+func (h *Handler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	var req PreviewTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 400)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 400)
+		return
+	}
+
+	dataJSON := json.RawMessage("{}")
+	if req.Data != nil {
+		b, err := json.Marshal(req.Data)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid data field", "INVALID_DATA", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 400)
+			return
+		}
+		dataJSON = b
+	}
+
+	notif := repo.Notification{
+		Type:   req.Type,
+		Title:  req.Title,
+		Body:   req.Body,
+		Icon:   req.Icon,
+		Url:    req.URL,
+		Locale: req.Locale,
+		Data:   dataJSON,
+	}
+
+	// Previewing a specific (possibly inactive) version pins it like a notification would
+	if req.TemplateID != nil {
+		tmpl, err := h.repo.GetTemplate(ctx, *req.TemplateID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				h.respondError(w, http.StatusNotFound, "template not found", "NOT_FOUND", nil)
+				metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 404)
+				return
+			}
+			h.logger.Error("failed to get template", zap.Error(err))
+			h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 500)
+			return
+		}
+		if tmpl.Type != req.Type {
+			h.respondError(w, http.StatusBadRequest, "template_id does not belong to type", "VALIDATION_ERROR", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 400)
+			return
+		}
+		notif.TemplateID = pgtype.UUID{Bytes: tmpl.ID, Valid: true}
+		if notif.Locale == nil {
+			notif.Locale = &tmpl.Locale
+		}
+	}
+
+	payload, content, err := webpush.BuildPayload(ctx, h.resolver, notif, nil, h.tracker, "")
+	var missing *templates.MissingVariablesError
+	if err != nil && !errors.As(err, &missing) {
+		h.logger.Error("failed to build preview payload", zap.Error(err), zap.String("type", req.Type))
+		h.respondError(w, http.StatusInternalServerError, "failed to render preview", "RENDER_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 500)
+		return
+	}
+
+	resp := PreviewTemplateResponse{
+		Payload:        payload,
+		PayloadSize:    len(payload),
+		MaxPayloadSize: webpush.MaxPayloadSize,
+		ExceedsLimit:   len(payload) > webpush.MaxPayloadSize,
+		Locale:         content.Locale,
+		TemplateID:     content.TemplateID,
+	}
+	if content.TemplateVersion != nil {
+		version := int(*content.TemplateVersion)
+		resp.TemplateVersion = &version
+	}
+	if missing != nil {
+		resp.MissingVariables = missing.Variables
+		resp.Errors = append(resp.Errors, missing.Error())
+	}
+	if req.Title == nil && content.Title == "" {
+		resp.Errors = append(resp.Errors, "title is empty: no template for type and no explicit title")
+	}
+	if req.Body == nil && content.Body == "" {
+		resp.Errors = append(resp.Errors, "body is empty: no template for type and no explicit body")
+	}
+	if resp.ExceedsLimit {
+		resp.Errors = append(resp.Errors, fmt.Sprintf("payload is %d bytes, exceeding the %d byte Web Push limit", resp.PayloadSize, webpush.MaxPayloadSize))
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/templates/preview", 200)
+	metrics.ObserveRequestDuration("POST", "/v1/templates/preview", 200, time.Since(start).Seconds())
+}
+
+// createTemplateVersion inserts a new version, deactivating the current
+// active version in the same transaction when the new one is active.
+func (h *Handler) createTemplateVersion(ctx context.Context, params repo.CreateTemplateParams) (repo.NotificationTemplate, error) {
+	var tmpl repo.NotificationTemplate
+	err := h.repo.WithTx(ctx, func(q *repo.Queries) error {
+		if params.IsActive {
+			if err := q.DeactivateTemplates(ctx, repo.DeactivateTemplatesParams{
+				Type:   params.Type,
+				Locale: params.Locale,
+			}); err != nil {
+				return err
+			}
+		}
+		var err error
+		tmpl, err = q.CreateTemplate(ctx, params)
+		return err
+	})
+	return tmpl, err
+}
+
+// loadTemplate parses the {id} URL parameter and loads the template,
+// writing the error response when it returns false.
+func (h *Handler) loadTemplate(w http.ResponseWriter, r *http.Request, method, path string) (repo.NotificationTemplate, bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid template ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal(method, path, 400)
+		return repo.NotificationTemplate{}, false
+	}
+
+	tmpl, err := h.repo.GetTemplate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.respondError(w, http.StatusNotFound, "template not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal(method, path, 404)
+			return repo.NotificationTemplate{}, false
+		}
+		h.logger.Error("failed to get template", zap.Error(err), zap.String("template_id", idStr))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal(method, path, 500)
+		return repo.NotificationTemplate{}, false
+	}
+	return tmpl, true
+}
+
+// respondTemplateWriteError maps template write failures to responses.
+func (h *Handler) respondTemplateWriteError(w http.ResponseWriter, err error, method, path string) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		h.respondError(w, http.StatusConflict, "concurrent template change, retry the request", "VERSION_CONFLICT", nil)
+		metrics.IncHTTPRequestsTotal(method, path, 409)
+		return
+	}
+	h.logger.Error("failed to write template", zap.Error(err))
+	h.respondError(w, http.StatusInternalServerError, "failed to write template", "WRITE_FAILED", nil)
+	metrics.IncHTTPRequestsTotal(method, path, 500)
+}
+
+// toTemplateResponse converts a template row to its API representation.
+func toTemplateResponse(t repo.NotificationTemplate) TemplateResponse {
+	return TemplateResponse{
+		ID:        t.ID,
+		Type:      t.Type,
+		Locale:    t.Locale,
+		Version:   int(t.Version),
+		Title:     t.Title,
+		Body:      t.Body,
+		URL:       t.Url,
+		Icon:      t.Icon,
+		IsActive:  t.IsActive,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package apihttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"notifications/internal/repo"
+	"notifications/internal/templates"
+	"notifications/internal/webpush"
+)
+
+func TestPreviewTemplateMatchesSend(t *testing.T) {
+	resolver := templates.NewResolver("en", nil)
+	h := NewHandler(nil, nil, nil, resolver, nil, nil, nil, nil, nil, 0, "", zap.NewNop())
+	title, locale := "Restock needed", "fr"
+
+	tests := []struct {
+		name        string
+		body        string
+		notif       repo.Notification
+		wantTitle   string
+		wantMissing []string
+	}{
+		{
+			name: "missing variable",
+			body: `{"type":"STOCK_REQUEST.NEW_REQUEST","locale":"fr","data":{"requestId":"abc","requestNumber":"SR-7","totalItems":3}}`,
+			notif: repo.Notification{
+				Type:   "STOCK_REQUEST.NEW_REQUEST",
+				Locale: &locale,
+				Data:   json.RawMessage(`{"requestId":"abc","requestNumber":"SR-7","totalItems":3}`),
+			},
+			wantTitle:   "Nouvelle demande de stock",
+			wantMissing: []string{"warehouse"},
+		},
+		{
+			name: "explicit title",
+			body: `{"type":"STOCK_REQUEST.APPROVED","title":"Restock needed","data":{"requestId":"abc","requestNumber":"SR-7"}}`,
+			notif: repo.Notification{
+				Type:  "STOCK_REQUEST.APPROVED",
+				Title: &title,
+				Data:  json.RawMessage(`{"requestId":"abc","requestNumber":"SR-7"}`),
+			},
+			wantTitle: title,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.PreviewTemplate(rec, httptest.NewRequest(http.MethodPost, "/v1/templates/preview", strings.NewReader(tt.body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("PreviewTemplate() status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			var resp PreviewTemplateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(resp.MissingVariables, tt.wantMissing) {
+				t.Errorf("missing_variables = %v, want %v", resp.MissingVariables, tt.wantMissing)
+			}
+
+			// The worker builds the payload the same way for a subscription
+			// without a locale of its own
+			sent, _, err := webpush.BuildPayload(context.Background(), resolver, tt.notif, nil, nil, "user-1")
+			if err != nil && tt.wantMissing == nil {
+				t.Fatalf("BuildPayload() error = %v", err)
+			}
+			var got, want map[string]interface{}
+			if err := json.Unmarshal(resp.Payload, &got); err != nil {
+				t.Fatalf("failed to decode preview payload: %v", err)
+			}
+			if err := json.Unmarshal(sent, &want); err != nil {
+				t.Fatalf("failed to decode sent payload: %v", err)
+			}
+			// Previews don't create a notification, so there's no ID to match
+			delete(got, "notification_id")
+			delete(want, "notification_id")
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("preview payload = %v, want sent payload %v", got, want)
+			}
+			if got["title"] != tt.wantTitle {
+				t.Errorf("payload title = %v, want %q", got["title"], tt.wantTitle)
+			}
+		})
+	}
+}
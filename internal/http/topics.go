@@ -0,0 +1,389 @@
+package apihttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"notifications/internal/metrics"
+	"notifications/internal/repo"
+	"notifications/internal/webhook"
+)
+
+// CreateTopic handles POST /v1/topics
+func (h *Handler) CreateTopic(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var req CreateTopicRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics", 400)
+		return
+	}
+
+	topic, err := h.repo.CreateTopic(r.Context(), repo.CreateTopicParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			h.respondError(w, http.StatusConflict, "topic already exists", "TOPIC_EXISTS", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/topics", 409)
+			return
+		}
+		h.logger.Error("failed to create topic", zap.Error(err), zap.String("topic", req.Name))
+		h.respondError(w, http.StatusInternalServerError, "failed to create topic", "CREATE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics", 500)
+		return
+	}
+
+	h.logger.Info("topic created", zap.String("topic", topic.Name))
+
+	h.respondJSON(w, http.StatusCreated, toTopicResponse(topic))
+	metrics.IncHTTPRequestsTotal("POST", "/v1/topics", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/topics", 201, time.Since(start).Seconds())
+}
+
+// ListTopics handles GET /v1/topics
+func (h *Handler) ListTopics(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/topics", 400)
+		return
+	}
+
+	topics, err := h.repo.ListTopics(r.Context(), repo.ListTopicsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		h.logger.Error("failed to list topics", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/topics", 500)
+		return
+	}
+
+	resp := ListTopicsResponse{
+		Topics: make([]TopicResponse, len(topics)),
+		Total:  len(topics),
+	}
+	for i, topic := range topics {
+		resp.Topics[i] = toTopicResponse(topic)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/topics", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/topics", 200, time.Since(start).Seconds())
+}
+
+// GetTopic handles GET /v1/topics/:name
+func (h *Handler) GetTopic(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	topic, ok := h.loadTopic(w, r, "GET", "/v1/topics/:name")
+	if !ok {
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, toTopicResponse(topic))
+	metrics.IncHTTPRequestsTotal("GET", "/v1/topics/:name", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/topics/:name", 200, time.Since(start).Seconds())
+}
+
+// DeleteTopic handles DELETE /v1/topics/:name. Its subscriptions are removed
+// with it; notifications already sent to the topic keep their topic list.
+func (h *Handler) DeleteTopic(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	name := chi.URLParam(r, "name")
+
+	deleted, err := h.repo.DeleteTopic(r.Context(), name)
+	if err != nil {
+		h.logger.Error("failed to delete topic", zap.Error(err), zap.String("topic", name))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete topic", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "topic not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name", 404)
+		return
+	}
+
+	h.logger.Info("topic deleted", zap.String("topic", name))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/topics/:name", 204, time.Since(start).Seconds())
+}
+
+// ListTopicSubscribers handles GET /v1/topics/:name/subscribers
+func (h *Handler) ListTopicSubscribers(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/topics/:name/subscribers", 400)
+		return
+	}
+
+	topic, ok := h.loadTopic(w, r, "GET", "/v1/topics/:name/subscribers")
+	if !ok {
+		return
+	}
+
+	subs, err := h.repo.ListTopicSubscribers(r.Context(), repo.ListTopicSubscribersParams{
+		Topic:  topic.Name,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		h.logger.Error("failed to list topic subscribers", zap.Error(err), zap.String("topic", topic.Name))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/topics/:name/subscribers", 500)
+		return
+	}
+
+	resp := ListTopicSubscribersResponse{
+		Topic:       topic.Name,
+		Subscribers: make([]TopicSubscriberResponse, len(subs)),
+		Total:       len(subs),
+	}
+	for i, sub := range subs {
+		resp.Subscribers[i] = TopicSubscriberResponse{UserID: sub.UserID, CreatedAt: sub.CreatedAt}
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/topics/:name/subscribers", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/topics/:name/subscribers", 200, time.Since(start).Seconds())
+}
+
+// SubscribeToTopic handles PUT /v1/topics/:name/subscribers/:user_id
+func (h *Handler) SubscribeToTopic(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	name := chi.URLParam(r, "name")
+	userID := chi.URLParam(r, "user_id")
+
+	if len(userID) > 255 {
+		h.respondError(w, http.StatusBadRequest, "user_id exceeds 255 characters", "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/topics/:name/subscribers/:user_id", 400)
+		return
+	}
+
+	err := h.repo.SubscribeToTopic(r.Context(), repo.SubscribeToTopicParams{Topic: name, UserID: userID})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			h.respondError(w, http.StatusNotFound, "topic not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("PUT", "/v1/topics/:name/subscribers/:user_id", 404)
+			return
+		}
+		h.logger.Error("failed to subscribe to topic", zap.Error(err), zap.String("topic", name), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to subscribe to topic", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("PUT", "/v1/topics/:name/subscribers/:user_id", 500)
+		return
+	}
+
+	h.logger.Info("user subscribed to topic", zap.String("topic", name), zap.String("user_id", userID))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("PUT", "/v1/topics/:name/subscribers/:user_id", 204)
+	metrics.ObserveRequestDuration("PUT", "/v1/topics/:name/subscribers/:user_id", 204, time.Since(start).Seconds())
+}
+
+// UnsubscribeFromTopic handles DELETE /v1/topics/:name/subscribers/:user_id
+func (h *Handler) UnsubscribeFromTopic(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	name := chi.URLParam(r, "name")
+	userID := chi.URLParam(r, "user_id")
+
+	deleted, err := h.repo.UnsubscribeFromTopic(r.Context(), repo.UnsubscribeFromTopicParams{Topic: name, UserID: userID})
+	if err != nil {
+		h.logger.Error("failed to unsubscribe from topic", zap.Error(err), zap.String("topic", name), zap.String("user_id", userID))
+		h.respondError(w, http.StatusInternalServerError, "failed to unsubscribe from topic", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/subscribers/:user_id", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "topic subscription not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/subscribers/:user_id", 404)
+		return
+	}
+
+	h.logger.Info("user unsubscribed from topic", zap.String("topic", name), zap.String("user_id", userID))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/subscribers/:user_id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/topics/:name/subscribers/:user_id", 204, time.Since(start).Seconds())
+}
+
+// ListTopicWebhooks handles GET /v1/topics/:name/webhooks
+func (h *Handler) ListTopicWebhooks(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	topic, ok := h.loadTopic(w, r, "GET", "/v1/topics/:name/webhooks")
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.repo.ListTopicWebhooks(r.Context(), topic.Name)
+	if err != nil {
+		h.logger.Error("failed to list topic webhooks", zap.Error(err), zap.String("topic", topic.Name))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("GET", "/v1/topics/:name/webhooks", 500)
+		return
+	}
+
+	resp := ListTopicWebhooksResponse{
+		Topic:    topic.Name,
+		Webhooks: make([]TopicWebhookResponse, len(webhooks)),
+	}
+	for i, hook := range webhooks {
+		resp.Webhooks[i] = toTopicWebhookResponse(hook)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+	metrics.IncHTTPRequestsTotal("GET", "/v1/topics/:name/webhooks", 200)
+	metrics.ObserveRequestDuration("GET", "/v1/topics/:name/webhooks", 200, time.Since(start).Seconds())
+}
+
+// CreateTopicWebhook handles POST /v1/topics/:name/webhooks. Notifications
+// sent to the topic on the webhook channel are POSTed to the URL once, signed
+// with a secret that is returned only in this response. Registering an
+// existing URL reactivates it and returns its secret.
+func (h *Handler) CreateTopicWebhook(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	name := chi.URLParam(r, "name")
+
+	var req TopicWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid JSON body", "INVALID_JSON", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics/:name/webhooks", 400)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error(), "VALIDATION_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics/:name/webhooks", 400)
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		h.logger.Error("failed to generate webhook secret", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics/:name/webhooks", 500)
+		return
+	}
+
+	hook, err := h.repo.CreateTopicWebhook(r.Context(), repo.CreateTopicWebhookParams{
+		Topic:  name,
+		Url:    req.URL,
+		Secret: secret,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			h.respondError(w, http.StatusNotFound, "topic not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal("POST", "/v1/topics/:name/webhooks", 404)
+			return
+		}
+		h.logger.Error("failed to save topic webhook", zap.Error(err), zap.String("topic", name))
+		h.respondError(w, http.StatusInternalServerError, "failed to save topic webhook", "WRITE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("POST", "/v1/topics/:name/webhooks", 500)
+		return
+	}
+
+	h.logger.Info("topic webhook saved", zap.String("topic", name), zap.String("webhook_id", hook.ID.String()))
+
+	resp := toTopicWebhookResponse(hook)
+	resp.Secret = hook.Secret
+	h.respondJSON(w, http.StatusCreated, resp)
+	metrics.IncHTTPRequestsTotal("POST", "/v1/topics/:name/webhooks", 201)
+	metrics.ObserveRequestDuration("POST", "/v1/topics/:name/webhooks", 201, time.Since(start).Seconds())
+}
+
+// DeleteTopicWebhook handles DELETE /v1/topics/:name/webhooks/:id
+func (h *Handler) DeleteTopicWebhook(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	name := chi.URLParam(r, "name")
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid webhook ID", "INVALID_ID", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/webhooks/:id", 400)
+		return
+	}
+
+	deleted, err := h.repo.DeleteTopicWebhook(r.Context(), repo.DeleteTopicWebhookParams{ID: id, Topic: name})
+	if err != nil {
+		h.logger.Error("failed to delete topic webhook", zap.Error(err), zap.String("webhook_id", id.String()))
+		h.respondError(w, http.StatusInternalServerError, "failed to delete topic webhook", "DELETE_FAILED", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/webhooks/:id", 500)
+		return
+	}
+	if deleted == 0 {
+		h.respondError(w, http.StatusNotFound, "topic webhook not found", "NOT_FOUND", nil)
+		metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/webhooks/:id", 404)
+		return
+	}
+
+	h.logger.Info("topic webhook deleted", zap.String("topic", name), zap.String("webhook_id", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.IncHTTPRequestsTotal("DELETE", "/v1/topics/:name/webhooks/:id", 204)
+	metrics.ObserveRequestDuration("DELETE", "/v1/topics/:name/webhooks/:id", 204, time.Since(start).Seconds())
+}
+
+// loadTopic fetches the topic named in the URL, responding with 404 or 500 on failure.
+func (h *Handler) loadTopic(w http.ResponseWriter, r *http.Request, method, path string) (repo.NotificationTopic, bool) {
+	name := chi.URLParam(r, "name")
+
+	topic, err := h.repo.GetTopic(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.respondError(w, http.StatusNotFound, "topic not found", "NOT_FOUND", nil)
+			metrics.IncHTTPRequestsTotal(method, path, 404)
+			return repo.NotificationTopic{}, false
+		}
+		h.logger.Error("failed to get topic", zap.Error(err), zap.String("topic", name))
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "INTERNAL_ERROR", nil)
+		metrics.IncHTTPRequestsTotal(method, path, 500)
+		return repo.NotificationTopic{}, false
+	}
+	return topic, true
+}
+
+// toTopicResponse converts a topic row to its API representation.
+func toTopicResponse(t repo.NotificationTopic) TopicResponse {
+	return TopicResponse{
+		Name:        t.Name,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt,
+	}
+}
+
+// toTopicWebhookResponse converts a topic webhook row to its API
+// representation, without its secret.
+func toTopicWebhookResponse(hook repo.TopicWebhook) TopicWebhookResponse {
+	return TopicWebhookResponse{
+		ID:        hook.ID,
+		Topic:     hook.Topic,
+		URL:       hook.Url,
+		IsActive:  hook.IsActive,
+		CreatedAt: hook.CreatedAt,
+		UpdatedAt: hook.UpdatedAt,
+	}
+}
@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"notifications/internal/callback"
+	"notifications/internal/repo"
+)
+
+// RollupNotificationStatus completes the notification's aggregate status once
+// every expected delivery has a terminal outcome, and queues the completion
+// callback when the notification has a callback URL. A recipient whose push
+// deliveries all failed but whose fallback is still pending keeps the
+// notification open until the fallback is sent. Dedupe keys claimed for
+// recipients that received nothing are released. Run it in a transaction
+// so the callback is queued exactly when the status commits.
+func RollupNotificationStatus(ctx context.Context, q *repo.Queries, notificationID uuid.UUID) (bool, error) {
+	completed, err := q.RollupNotificationStatus(ctx, notificationID)
+	if err != nil || completed == 0 {
+		return false, err
+	}
+
+	// Recipients that received nothing don't hold their dedupe key
+	if _, err := q.ReleaseUndeliveredDedupeClaims(ctx, notificationID); err != nil {
+		return false, fmt.Errorf("failed to release dedupe claims: %w", err)
+	}
+
+	notif, err := q.GetNotification(ctx, notificationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get notification: %w", err)
+	}
+	if notif.CallbackUrl == nil {
+		return true, nil
+	}
+	counts, err := q.GetNotificationDeliveryCounts(ctx, notificationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get delivery counts: %w", err)
+	}
+	if err := createCallback(ctx, q, *notif.CallbackUrl, notif.CreatedBy, callback.NotificationPayload(notif, &counts)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateDeliveryAttempt records a delivery attempt and queues its callback
+// when the notification asks for one per attempt
+func CreateDeliveryAttempt(ctx context.Context, q *repo.Queries, params repo.CreateDeliveryAttemptParams) (repo.NotificationAttempt, error) {
+	attempt, err := q.CreateDeliveryAttempt(ctx, params)
+	if err != nil {
+		return repo.NotificationAttempt{}, err
+	}
+
+	settings, err := q.GetNotificationCallback(ctx, params.NotificationID)
+	if err != nil {
+		return repo.NotificationAttempt{}, fmt.Errorf("failed to get notification callback: %w", err)
+	}
+	if settings.CallbackUrl == nil || !settings.CallbackAttempts {
+		return attempt, nil
+	}
+	if err := createCallback(ctx, q, *settings.CallbackUrl, settings.CreatedBy, callback.AttemptPayload(settings.Type, attempt)); err != nil {
+		return repo.NotificationAttempt{}, err
+	}
+	return attempt, nil
+}
+
+// CreateCancelledCallback queues the callback for a notification that was
+// just cancelled, when it has a callback URL
+func CreateCancelledCallback(ctx context.Context, q *repo.Queries, notificationID uuid.UUID) error {
+	notif, err := q.GetNotification(ctx, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+	if notif.CallbackUrl == nil {
+		return nil
+	}
+	return createCallback(ctx, q, *notif.CallbackUrl, notif.CreatedBy, callback.NotificationPayload(notif, nil))
+}
+
+// createCallback inserts the outbox row that publishes a callback, signed
+// for createdBy, the API client that created the notification
+func createCallback(ctx context.Context, q *repo.Queries, callbackURL string, createdBy *string, payload callback.Payload) error {
+	var keyID string
+	if createdBy != nil {
+		keyID = *createdBy
+	}
+	msg, err := NewCallbackOutboxMessage(callbackURL, keyID, payload)
+	if err != nil {
+		return err
+	}
+	if _, err := q.CreateOutboxMessage(ctx, msg); err != nil {
+		return fmt.Errorf("failed to create callback outbox message: %w", err)
+	}
+	return nil
+}
+
+// handleSendCallback POSTs a status callback to the producer. Failures are
+// retried with the callback backoff unless the producer rejected the
+// callback outright.
+func (w *Worker) handleSendCallback(ctx context.Context, task *asynq.Task) error {
+	var payload CallbackPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		w.logger.Error("Failed to unmarshal task payload",
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	result, err := w.callbacks.Send(ctx, payload.URL, payload.NotificationID, payload.Event, payload.KeyID, payload.Body)
+	if err != nil {
+		return fmt.Errorf("failed to send callback: %w", err)
+	}
+	if result.Success {
+		w.logger.Info("Sent callback",
+			slog.String("notification_id", payload.NotificationID.String()),
+			slog.String("event", payload.Event),
+			slog.Int("http_status", result.HTTPStatus),
+		)
+		return nil
+	}
+
+	w.logger.Warn("Callback failed",
+		slog.String("notification_id", payload.NotificationID.String()),
+		slog.String("event", payload.Event),
+		slog.String("error", result.Error),
+	)
+	callbackErr := fmt.Errorf("callback failed: %s", result.Error)
+	switch ClassifyResult(result) {
+	case FailurePermanent:
+		return fmt.Errorf("%w: %w", callbackErr, asynq.SkipRetry)
+	case FailureRateLimited:
+		return &RetryAfterError{Delay: result.RetryAfter, Err: callbackErr}
+	default:
+		return callbackErr
+	}
+}
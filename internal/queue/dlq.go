@@ -0,0 +1,271 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// ErrDeadLetterNotFound is returned when a task is not in the dead-letter queue
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// ErrDeadLetterNotRemoved is returned by Requeue when the replacement task was
+// enqueued but the archived task could not be deleted
+var ErrDeadLetterNotRemoved = errors.New("requeued dead letter not removed")
+
+// requeueTaskIDSep separates a task's original ID from its requeue count
+const requeueTaskIDSep = ":requeue:"
+
+// DeadLetter is a task that exhausted its retries and was archived by asynq
+type DeadLetter struct {
+	ID           string
+	Queue        string
+	Type         string
+	Payload      json.RawMessage
+	LastError    string
+	LastFailedAt time.Time
+	Retried      int
+	MaxRetry     int
+	Delivery     *DeliverNotificationPayload // Set for delivery tasks
+}
+
+// Inspector exposes asynq's archived task set as a dead-letter queue, and
+// removes scheduled tasks that were cancelled before they ran
+type Inspector struct {
+	inspector *asynq.Inspector
+	client    *asynq.Client // Re-enqueues requeued dead letters
+}
+
+// NewInspector creates a new dead-letter queue inspector
+func NewInspector(redisAddr string) *Inspector {
+	opt := asynq.RedisClientOpt{Addr: redisAddr}
+	return &Inspector{
+		inspector: asynq.NewInspector(opt),
+		client:    asynq.NewClient(opt),
+	}
+}
+
+// Close closes the inspector
+func (i *Inspector) Close() error {
+	return errors.Join(i.inspector.Close(), i.client.Close())
+}
+
+// ListDeadLetters returns archived tasks in queue, or in every queue when
+// queue is empty, oldest failure first
+func (i *Inspector) ListDeadLetters(queue string, limit, offset int) ([]DeadLetter, error) {
+	queues, err := i.queues(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	var letters []DeadLetter
+	for _, q := range queues {
+		// Fetch enough from each queue to cover the requested window
+		tasks, err := i.inspector.ListArchivedTasks(q, asynq.PageSize(offset+limit), asynq.Page(1))
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list archived tasks in %s: %w", q, err)
+		}
+		for _, task := range tasks {
+			letters = append(letters, toDeadLetter(task))
+		}
+	}
+
+	// Each queue lists its oldest failures first; merge them into one order
+	// so offset and limit page across queues consistently
+	sort.SliceStable(letters, func(a, b int) bool {
+		if !letters[a].LastFailedAt.Equal(letters[b].LastFailedAt) {
+			return letters[a].LastFailedAt.Before(letters[b].LastFailedAt)
+		}
+		if letters[a].Queue != letters[b].Queue {
+			return letters[a].Queue < letters[b].Queue
+		}
+		return letters[a].ID < letters[b].ID
+	})
+
+	if offset >= len(letters) {
+		return []DeadLetter{}, nil
+	}
+	letters = letters[offset:]
+	if len(letters) > limit {
+		letters = letters[:limit]
+	}
+	return letters, nil
+}
+
+// CountDeadLetters returns the number of archived tasks in queue, or in every
+// queue when queue is empty
+func (i *Inspector) CountDeadLetters(queue string) (int, error) {
+	queues, err := i.queues(queue)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, q := range queues {
+		info, err := i.inspector.GetQueueInfo(q)
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get queue info for %s: %w", q, err)
+		}
+		total += info.Archived
+	}
+	return total, nil
+}
+
+// GetDeadLetter returns a single archived task
+func (i *Inspector) GetDeadLetter(queue, id string) (DeadLetter, error) {
+	task, err := i.inspector.GetTaskInfo(queue, id)
+	if err != nil {
+		return DeadLetter{}, mapInspectorError(err)
+	}
+	if task.State != asynq.TaskStateArchived {
+		return DeadLetter{}, ErrDeadLetterNotFound
+	}
+	return toDeadLetter(task), nil
+}
+
+// Requeue replaces an archived task with a fresh task with the same payload
+// and options under a new ID (see RequeueTaskID), so it gets its full retry
+// budget again; asynq's own RunTask would keep the exhausted retry count and
+// archive the task after a single failure. The replacement is enqueued before
+// the archived task is deleted, so a failure never loses the dead letter. If
+// only the delete fails, the new task ID is returned with
+// ErrDeadLetterNotRemoved; requeueing the leftover again just removes it.
+func (i *Inspector) Requeue(queue, id string) (string, error) {
+	task, err := i.inspector.GetTaskInfo(queue, id)
+	if err != nil {
+		return "", mapInspectorError(err)
+	}
+	if task.State != asynq.TaskStateArchived {
+		return "", ErrDeadLetterNotFound
+	}
+
+	newID := RequeueTaskID(task.ID)
+	opts := []asynq.Option{
+		asynq.TaskID(newID),
+		asynq.Queue(task.Queue),
+		asynq.MaxRetry(task.MaxRetry),
+	}
+	if task.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(task.Timeout))
+	}
+	if task.Retention > 0 {
+		opts = append(opts, asynq.Retention(task.Retention))
+	}
+	// A conflict means an earlier requeue enqueued the replacement but
+	// failed to delete the dead letter
+	_, err = i.client.Enqueue(asynq.NewTask(task.Type, task.Payload), opts...)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return "", fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	if err := i.inspector.DeleteTask(queue, id); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+		return newID, fmt.Errorf("%w: %w", ErrDeadLetterNotRemoved, err)
+	}
+	return newID, nil
+}
+
+// RequeueTaskID returns the ID a requeued task runs under: the original ID
+// with a ":requeue:<n>" suffix counting how often it was requeued
+func RequeueTaskID(id string) string {
+	n := 0
+	if i := strings.LastIndex(id, requeueTaskIDSep); i >= 0 {
+		if prev, err := strconv.Atoi(id[i+len(requeueTaskIDSep):]); err == nil {
+			id, n = id[:i], prev
+		}
+	}
+	return fmt.Sprintf("%s%s%d", id, requeueTaskIDSep, n+1)
+}
+
+// Purge permanently deletes an archived task
+func (i *Inspector) Purge(queue, id string) error {
+	if _, err := i.GetDeadLetter(queue, id); err != nil {
+		return err
+	}
+	if err := i.inspector.DeleteTask(queue, id); err != nil {
+		return mapInspectorError(err)
+	}
+	return nil
+}
+
+// PurgeAll deletes every archived task in queue, or in every queue when queue is empty
+func (i *Inspector) PurgeAll(queue string) (int, error) {
+	queues, err := i.queues(queue)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, q := range queues {
+		n, err := i.inspector.DeleteAllArchivedTasks(q)
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		}
+		if err != nil {
+			return total, fmt.Errorf("failed to purge archived tasks in %s: %w", q, err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// DeleteTask removes a task that has not run yet, such as a scheduled fan-out.
+// Tasks that no longer exist are ignored.
+func (i *Inspector) DeleteTask(queue, id string) error {
+	err := i.inspector.DeleteTask(queue, id)
+	if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return nil
+	}
+	return err
+}
+
+// queues returns the queue to inspect, or all known queues when queue is empty
+func (i *Inspector) queues(queue string) ([]string, error) {
+	if queue != "" {
+		return []string{queue}, nil
+	}
+	queues, err := i.inspector.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+	return queues, nil
+}
+
+func toDeadLetter(task *asynq.TaskInfo) DeadLetter {
+	letter := DeadLetter{
+		ID:           task.ID,
+		Queue:        task.Queue,
+		Type:         task.Type,
+		Payload:      json.RawMessage(task.Payload),
+		LastError:    task.LastErr,
+		LastFailedAt: task.LastFailedAt,
+		Retried:      task.Retried,
+		MaxRetry:     task.MaxRetry,
+	}
+	if task.Type == TypeDeliverNotification {
+		var payload DeliverNotificationPayload
+		if err := json.Unmarshal(task.Payload, &payload); err == nil {
+			letter.Delivery = &payload
+		}
+	}
+	return letter
+}
+
+// mapInspectorError maps asynq's not-found errors to ErrDeadLetterNotFound
+func mapInspectorError(err error) error {
+	if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return ErrDeadLetterNotFound
+	}
+	return err
+}
@@ -0,0 +1,20 @@
+package queue
+
+import "testing"
+
+func TestRequeueTaskID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"deliver:n:t", "deliver:n:t:requeue:1"},
+		{"deliver:n:t:requeue:1", "deliver:n:t:requeue:2"},
+		{"deliver:n:t:requeue:9", "deliver:n:t:requeue:10"},
+		{"deliver:n:t:requeue:x", "deliver:n:t:requeue:x:requeue:1"},
+	}
+	for _, tt := range tests {
+		if got := RequeueTaskID(tt.id); got != tt.want {
+			t.Errorf("RequeueTaskID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
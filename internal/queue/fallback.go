@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"notifications/internal/channel"
+	"notifications/internal/repo"
+)
+
+// routingPolicy returns the routing policy for a notification type, or nil
+// when the type has none
+func (w *Worker) routingPolicy(ctx context.Context, notificationType string) (*repo.NotificationRoutingPolicy, error) {
+	policy, err := w.repo.GetRoutingPolicy(ctx, notificationType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routing policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// fallbackChannel returns the channel recipients fall back to under policy,
+// or "" when none applies. Fallbacks only back up push, and never to a
+// channel the notification is already sent on.
+func fallbackChannel(notif repo.Notification, policy *repo.NotificationRoutingPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	channels := notificationChannels(notif)
+	if !slices.Contains(channels, channel.Push) || slices.Contains(channels, policy.FallbackChannel) {
+		return ""
+	}
+	return policy.FallbackChannel
+}
+
+// isFallbackOutcome reports whether a push attempt status means the device
+// was not reached and won't be retried
+func isFallbackOutcome(status string) bool {
+	return status == AttemptStatusFailed || status == AttemptStatusPruned || status == AttemptStatusSkipped
+}
+
+// triggerFallback sends the recipient's fallback once every push delivery to
+// them failed, was pruned or skipped, in its own transaction; see
+// triggerFallbackTx.
+func (w *Worker) triggerFallback(ctx context.Context, notificationID uuid.UUID, userID, priority string) error {
+	return w.repo.WithTx(ctx, func(q *repo.Queries) error {
+		fallback, err := lockPendingFallback(ctx, q, notificationID, userID)
+		if err != nil || fallback == nil {
+			return err
+		}
+		return w.triggerFallbackTx(ctx, q, *fallback, priority)
+	})
+}
+
+// lockPendingFallback returns the recipient's pending fallback, locked until
+// the transaction ends, or nil when there is none. Push attempts that may
+// trigger it take the lock before they are inserted, so the last of several
+// concurrent failures sees all the others.
+func lockPendingFallback(ctx context.Context, q *repo.Queries, notificationID uuid.UUID, userID string) (*repo.NotificationFallback, error) {
+	fallback, err := q.GetPendingFallback(ctx, repo.GetPendingFallbackParams{
+		NotificationID: notificationID,
+		UserID:         userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fallback: %w", err)
+	}
+	return &fallback, nil
+}
+
+// triggerFallbackTx marks the locked fallback triggered and queues its
+// deliveries through the outbox in the same transaction, so a crash can't
+// leave it triggered without them. It is a no-op while push deliveries are in
+// flight or after one was delivered. Fallback deliveries are linked to the
+// fallback row, which counts them towards the notification's expected
+// deliveries; until then RollupNotificationStatus keeps the notification
+// open, so it never completes before its fallback is sent.
+func (w *Worker) triggerFallbackTx(ctx context.Context, q *repo.Queries, fallback repo.NotificationFallback, priority string) error {
+	contacts, err := q.ListActiveContactPointsByUser(ctx, repo.ListActiveContactPointsByUserParams{
+		UserID:  fallback.UserID,
+		Channel: fallback.Channel,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list %s contact points for user %s: %w", fallback.Channel, fallback.UserID, err)
+	}
+
+	processAt := time.Now().Add(time.Duration(fallback.DelaySeconds) * time.Second)
+	fallback, err = q.TriggerFallback(ctx, repo.TriggerFallbackParams{
+		Deliveries:     int32(len(contacts)),
+		ProcessAt:      &processAt,
+		NotificationID: fallback.NotificationID,
+		UserID:         fallback.UserID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil // Not every push delivery has failed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to trigger fallback: %w", err)
+	}
+
+	for _, contact := range contacts {
+		contactID, fallbackID := contact.ID, fallback.ID
+		msg, err := NewDeliveryOutboxMessage(DeliverNotificationPayload{
+			NotificationID: fallback.NotificationID,
+			UserID:         fallback.UserID,
+			Priority:       priority,
+			Channel:        fallback.Channel,
+			ContactPointID: &contactID,
+			FallbackID:     &fallbackID,
+		}, processAt)
+		if err != nil {
+			return err
+		}
+		if _, err := q.CreateOutboxMessage(ctx, msg); err != nil {
+			return fmt.Errorf("failed to create fallback outbox message: %w", err)
+		}
+	}
+
+	w.logger.Info("Triggered channel fallback",
+		slog.String("notification_id", fallback.NotificationID.String()),
+		slog.String("user_id", fallback.UserID),
+		slog.String("channel", fallback.Channel),
+		slog.Int("deliveries", len(contacts)),
+		slog.Time("process_at", processAt),
+	)
+	return nil
+}
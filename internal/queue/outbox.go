@@ -0,0 +1,147 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"notifications/internal/repo"
+)
+
+// OutboxRelay publishes pending notification_outbox rows to asynq.
+// Rows are claimed with FOR UPDATE SKIP LOCKED, so several relays can run
+// side by side, and a row is only marked dispatched after it was enqueued.
+type OutboxRelay struct {
+	repo      *repo.Repository
+	client    *Client
+	logger    *slog.Logger
+	interval  time.Duration
+	batchSize int32
+	retention time.Duration
+}
+
+// OutboxRelayConfig contains configuration for the outbox relay
+type OutboxRelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Retention    time.Duration // How long dispatched rows are kept
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(
+	cfg OutboxRelayConfig,
+	repository *repo.Repository,
+	client *Client,
+	logger *slog.Logger,
+) *OutboxRelay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+
+	return &OutboxRelay{
+		repo:      repository,
+		client:    client,
+		logger:    logger,
+		interval:  cfg.PollInterval,
+		batchSize: int32(cfg.BatchSize),
+		retention: cfg.Retention,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	r.logger.Info("Starting outbox relay",
+		slog.Duration("poll_interval", r.interval),
+		slog.Int("batch_size", int(r.batchSize)),
+	)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		// Drain full batches before waiting for the next tick
+		for {
+			n, err := r.DispatchBatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.Error("Failed to dispatch outbox batch", slog.String("error", err.Error()))
+				}
+				break
+			}
+			if n < int(r.batchSize) {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) >= time.Hour {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Stopping outbox relay")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch claims up to one batch of pending rows and publishes them.
+// It returns the number of rows dispatched. Rows that fail to publish stay
+// pending with their error recorded and are retried on the next poll.
+func (r *OutboxRelay) DispatchBatch(ctx context.Context) (int, error) {
+	dispatched := 0
+	err := r.repo.WithTx(ctx, func(q *repo.Queries) error {
+		messages, err := q.ClaimPendingOutboxMessages(ctx, r.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox messages: %w", err)
+		}
+
+		for _, msg := range messages {
+			if err := r.client.PublishOutboxMessage(ctx, msg); err != nil {
+				r.logger.Warn("Failed to publish outbox message",
+					slog.String("outbox_id", msg.ID.String()),
+					slog.String("notification_id", msg.NotificationID.String()),
+					slog.String("error", err.Error()),
+				)
+				errMsg := err.Error()
+				if err := q.RecordOutboxFailure(ctx, repo.RecordOutboxFailureParams{
+					ID:        msg.ID,
+					LastError: &errMsg,
+				}); err != nil {
+					return fmt.Errorf("failed to record outbox failure: %w", err)
+				}
+				continue
+			}
+
+			if err := q.MarkOutboxMessageDispatched(ctx, msg.ID); err != nil {
+				return fmt.Errorf("failed to mark outbox message dispatched: %w", err)
+			}
+			dispatched++
+		}
+
+		return nil
+	})
+	return dispatched, err
+}
+
+// cleanup deletes dispatched rows older than the retention period
+func (r *OutboxRelay) cleanup(ctx context.Context) {
+	deleted, err := r.repo.DeleteDispatchedOutboxMessages(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		r.logger.Error("Failed to clean up outbox", slog.String("error", err.Error()))
+		return
+	}
+	if deleted > 0 {
+		r.logger.Info("Cleaned up dispatched outbox messages", slog.Int64("deleted", deleted))
+	}
+}
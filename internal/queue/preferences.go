@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"strings"
+
+	"notifications/internal/repo"
+)
+
+// PreferenceAllows reports whether a user's preferences allow a notification
+// type. The most specific matching pattern wins: an exact type, then the
+// longest "PREFIX.*" pattern, then "*". Types with no matching pattern are allowed.
+func PreferenceAllows(prefs []repo.UserNotificationPreference, notificationType string) bool {
+	allowed := true
+	best := -1
+	for _, pref := range prefs {
+		specificity := patternSpecificity(pref.TypePattern, notificationType)
+		if specificity > best {
+			best = specificity
+			allowed = pref.Enabled
+		}
+	}
+	return allowed
+}
+
+// patternSpecificity returns how specifically pattern matches notificationType,
+// or -1 when it doesn't match
+func patternSpecificity(pattern, notificationType string) int {
+	switch {
+	case pattern == notificationType:
+		return len(pattern) + 1 // An exact match beats any wildcard
+	case pattern == "*":
+		return 0
+	case strings.HasSuffix(pattern, ".*"):
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(notificationType, prefix) {
+			return len(prefix)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// quietHoursDeferral returns when a delivery to the user may be sent because
+// it falls in their quiet hours, or the zero time when it can be sent now
+func (w *Worker) quietHoursDeferral(ctx context.Context, userID string, now time.Time) (time.Time, error) {
+	qh, err := w.repo.GetQuietHours(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+	if !qh.Enabled {
+		return time.Time{}, nil
+	}
+
+	loc := w.defaultTimezone
+	if qh.Timezone != nil && *qh.Timezone != "" {
+		if userLoc, err := time.LoadLocation(*qh.Timezone); err == nil {
+			loc = userLoc
+		} else {
+			w.logger.Warn("Invalid quiet hours timezone, using default",
+				slog.String("user_id", userID),
+				slog.String("timezone", *qh.Timezone),
+			)
+		}
+	}
+
+	end, ok := quietHoursEnd(now, qh.StartTime, qh.EndTime, loc)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return end, nil
+}
+
+// quietHoursEnd returns when the quiet-hours window containing now ends, or
+// false when now is outside the window. start and end are "HH:MM" in loc; a
+// window whose start is after its end wraps past midnight.
+func quietHoursEnd(now time.Time, start, end string, loc *time.Location) (time.Time, bool) {
+	startClock, err := time.Parse("15:04", start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	endClock, err := time.Parse("15:04", end)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	current := local.Hour()*60 + local.Minute()
+	from := startClock.Hour()*60 + startClock.Minute()
+	until := endClock.Hour()*60 + endClock.Minute()
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), endClock.Hour(), endClock.Minute(), 0, 0, loc)
+
+	switch {
+	case from < until && current >= from && current < until:
+		return endToday, true
+	case from > until && current >= from:
+		return endToday.AddDate(0, 0, 1), true
+	case from > until && current < until:
+		return endToday, true
+	default:
+		return time.Time{}, false
+	}
+}
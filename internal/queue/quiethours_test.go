@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursEnd(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	at := func(loc *time.Location, year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		start, end string
+		loc        *time.Location
+		want       time.Time
+		wantOK     bool
+	}{
+		{"same day, inside", at(time.UTC, 2025, 6, 10, 13, 30), "12:00", "14:00", time.UTC, at(time.UTC, 2025, 6, 10, 14, 0), true},
+		{"same day, at start", at(time.UTC, 2025, 6, 10, 12, 0), "12:00", "14:00", time.UTC, at(time.UTC, 2025, 6, 10, 14, 0), true},
+		{"same day, at end", at(time.UTC, 2025, 6, 10, 14, 0), "12:00", "14:00", time.UTC, time.Time{}, false},
+		{"same day, before", at(time.UTC, 2025, 6, 10, 9, 0), "12:00", "14:00", time.UTC, time.Time{}, false},
+		{"wrapping, before midnight", at(time.UTC, 2025, 6, 10, 23, 15), "22:00", "07:00", time.UTC, at(time.UTC, 2025, 6, 11, 7, 0), true},
+		{"wrapping, after midnight", at(time.UTC, 2025, 6, 11, 3, 0), "22:00", "07:00", time.UTC, at(time.UTC, 2025, 6, 11, 7, 0), true},
+		{"wrapping, outside", at(time.UTC, 2025, 6, 11, 12, 0), "22:00", "07:00", time.UTC, time.Time{}, false},
+		{"wrapping, across month end", at(time.UTC, 2025, 6, 30, 22, 30), "22:00", "07:00", time.UTC, at(time.UTC, 2025, 7, 1, 7, 0), true},
+		{"start equals end", at(time.UTC, 2025, 6, 10, 12, 0), "12:00", "12:00", time.UTC, time.Time{}, false},
+		{"malformed start", at(time.UTC, 2025, 6, 10, 13, 0), "12", "14:00", time.UTC, time.Time{}, false},
+		{"malformed end", at(time.UTC, 2025, 6, 10, 13, 0), "12:00", "25:00", time.UTC, time.Time{}, false},
+		{"evaluated in the user's zone", at(time.UTC, 2025, 6, 11, 2, 0), "21:00", "23:00", newYork, at(newYork, 2025, 6, 10, 23, 0), true},
+		// Clocks go forward at 02:00 on 9 March 2025, so the window is an hour shorter
+		{"dst starts overnight", at(newYork, 2025, 3, 8, 23, 0), "22:00", "07:00", newYork, time.Date(2025, 3, 9, 11, 0, 0, 0, time.UTC), true},
+		// Clocks go back at 02:00 on 2 November 2025, so the window is an hour longer
+		{"dst ends overnight", at(newYork, 2025, 11, 1, 23, 0), "22:00", "07:00", newYork, time.Date(2025, 11, 2, 12, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := quietHoursEnd(tt.now, tt.start, tt.end, tt.loc)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("quietHoursEnd() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"notifications/internal/channel"
+)
+
+// RetryPolicy controls how often and how quickly a delivery is retried
+type RetryPolicy struct {
+	MaxRetry  int
+	BaseDelay time.Duration // Delay before the first retry, doubled on each attempt
+	MaxDelay  time.Duration // Upper bound for a single backoff
+}
+
+// callbackRetryPolicy retries producer callbacks over several hours before
+// they are dead-lettered
+var callbackRetryPolicy = RetryPolicy{MaxRetry: 12, BaseDelay: 30 * time.Second, MaxDelay: 4 * time.Hour}
+
+// RetryPolicies maps notification priorities to retry policies
+type RetryPolicies map[string]RetryPolicy
+
+// DefaultRetryPolicies returns the built-in policies. More urgent priorities
+// retry sooner and more often.
+func DefaultRetryPolicies() RetryPolicies {
+	return RetryPolicies{
+		PriorityCritical: {MaxRetry: 8, BaseDelay: 5 * time.Second, MaxDelay: 10 * time.Minute},
+		PriorityHigh:     {MaxRetry: 5, BaseDelay: 10 * time.Second, MaxDelay: 30 * time.Minute},
+		PriorityNormal:   {MaxRetry: 3, BaseDelay: 30 * time.Second, MaxDelay: time.Hour},
+		PriorityLow:      {MaxRetry: 2, BaseDelay: time.Minute, MaxDelay: 2 * time.Hour},
+	}
+}
+
+// NewRetryPolicies overlays per-priority overrides on the default policies.
+// Priorities missing from a map keep their default value.
+func NewRetryPolicies(maxRetry map[string]int, baseDelay, maxDelay map[string]time.Duration) RetryPolicies {
+	policies := DefaultRetryPolicies()
+	for priority, policy := range policies {
+		if n, ok := maxRetry[priority]; ok && n >= 0 {
+			policy.MaxRetry = n
+		}
+		if d, ok := baseDelay[priority]; ok && d > 0 {
+			policy.BaseDelay = d
+		}
+		if d, ok := maxDelay[priority]; ok && d > 0 {
+			policy.MaxDelay = d
+		}
+		policies[priority] = policy
+	}
+	return policies
+}
+
+// For returns the policy for a priority, falling back to the normal policy
+func (p RetryPolicies) For(priority string) RetryPolicy {
+	if policy, ok := p[priority]; ok {
+		return policy
+	}
+	if policy, ok := p[PriorityNormal]; ok {
+		return policy
+	}
+	return DefaultRetryPolicies()[PriorityNormal]
+}
+
+// Backoff returns the delay before retry number n (0-based) using
+// exponential backoff with full jitter, capped at MaxDelay.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	ceiling := p.MaxDelay
+	if n < 32 {
+		if d := p.BaseDelay << n; d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	// Full jitter spreads retries from many deliveries failing at once
+	return time.Duration(rand.Int64N(int64(ceiling))) + time.Second
+}
+
+// FailureClass describes how a failed delivery should be retried
+type FailureClass int
+
+const (
+	// FailureRetryable failures (5xx, network errors) use exponential backoff
+	FailureRetryable FailureClass = iota
+	// FailureRateLimited failures (429) wait for the push service's Retry-After
+	FailureRateLimited
+	// FailurePermanent failures (400, 401, 403, 413) are never retried
+	FailurePermanent
+)
+
+// ClassifyResult classifies a failed delivery result
+func ClassifyResult(result *channel.DeliveryResult) FailureClass {
+	if result.Permanent {
+		return FailurePermanent
+	}
+	switch result.HTTPStatus {
+	case http.StatusTooManyRequests:
+		return FailureRateLimited
+	case http.StatusBadRequest,
+		http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusRequestEntityTooLarge:
+		return FailurePermanent
+	default:
+		return FailureRetryable
+	}
+}
+
+// RetryAfterError asks the worker to retry a task after a specific delay
+type RetryAfterError struct {
+	Delay time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.Delay)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// retryDelay implements asynq.RetryDelayFunc. Rate-limited deliveries wait
+// for Retry-After (or the backoff, whichever is longer); everything else uses
+// the backoff of the task's priority, or the callback policy for callbacks.
+func (p RetryPolicies) retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	var delay time.Duration
+	if task.Type() == TypeSendCallback {
+		delay = callbackRetryPolicy.Backoff(n)
+	} else {
+		priority := PriorityNormal
+		var payload struct {
+			Priority string `json:"priority"`
+		}
+		if json.Unmarshal(task.Payload(), &payload) == nil && payload.Priority != "" {
+			priority = payload.Priority
+		}
+		delay = p.For(priority).Backoff(n)
+	}
+
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.Delay > delay {
+		delay = retryAfter.Delay
+	}
+	return delay
+}
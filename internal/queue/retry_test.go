@@ -0,0 +1,127 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"notifications/internal/channel"
+)
+
+func TestClassifyResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result channel.DeliveryResult
+		want   FailureClass
+	}{
+		{"bad request", channel.DeliveryResult{HTTPStatus: http.StatusBadRequest}, FailurePermanent},
+		{"unauthorized", channel.DeliveryResult{HTTPStatus: http.StatusUnauthorized}, FailurePermanent},
+		{"forbidden", channel.DeliveryResult{HTTPStatus: http.StatusForbidden}, FailurePermanent},
+		{"payload too large", channel.DeliveryResult{HTTPStatus: http.StatusRequestEntityTooLarge}, FailurePermanent},
+		{"rate limited", channel.DeliveryResult{HTTPStatus: http.StatusTooManyRequests}, FailureRateLimited},
+		{"rate limited with retry-after", channel.DeliveryResult{HTTPStatus: http.StatusTooManyRequests, RetryAfter: time.Minute}, FailureRateLimited},
+		{"not found", channel.DeliveryResult{HTTPStatus: http.StatusNotFound}, FailureRetryable},
+		{"server error", channel.DeliveryResult{HTTPStatus: http.StatusInternalServerError}, FailureRetryable},
+		{"unavailable", channel.DeliveryResult{HTTPStatus: http.StatusServiceUnavailable}, FailureRetryable},
+		{"network error", channel.DeliveryResult{Error: "connection refused"}, FailureRetryable},
+		{"marked permanent", channel.DeliveryResult{HTTPStatus: http.StatusServiceUnavailable, Permanent: true}, FailurePermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyResult(&tt.result); got != tt.want {
+				t.Errorf("ClassifyResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffJitterBounds(t *testing.T) {
+	for priority, policy := range DefaultRetryPolicies() {
+		for n := 0; n <= policy.MaxRetry+2; n++ {
+			ceiling := policy.MaxDelay
+			if d := policy.BaseDelay << n; d < ceiling {
+				ceiling = d
+			}
+			for range 200 {
+				got := policy.Backoff(n)
+				if got < time.Second || got >= ceiling+time.Second {
+					t.Fatalf("%s: Backoff(%d) = %s, want within [1s, %s)", priority, n, got, ceiling+time.Second)
+				}
+			}
+		}
+	}
+}
+
+func TestBackoffCappedAtMaxDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetry: 3, BaseDelay: time.Minute, MaxDelay: 5 * time.Minute}
+	for _, n := range []int{3, 10, 31, 32, 100} {
+		for range 200 {
+			if got := policy.Backoff(n); got >= policy.MaxDelay+time.Second {
+				t.Fatalf("Backoff(%d) = %s, want below %s", n, got, policy.MaxDelay+time.Second)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyMaxRetry(t *testing.T) {
+	defaults := DefaultRetryPolicies()
+	want := map[string]int{
+		PriorityCritical: 8,
+		PriorityHigh:     5,
+		PriorityNormal:   3,
+		PriorityLow:      2,
+	}
+	for priority, n := range want {
+		if got := defaults.For(priority).MaxRetry; got != n {
+			t.Errorf("For(%q).MaxRetry = %d, want %d", priority, got, n)
+		}
+	}
+	if got := defaults.For("unknown").MaxRetry; got != want[PriorityNormal] {
+		t.Errorf("For(unknown).MaxRetry = %d, want the normal policy's %d", got, want[PriorityNormal])
+	}
+
+	policies := NewRetryPolicies(
+		map[string]int{PriorityHigh: 0, PriorityLow: -1},
+		map[string]time.Duration{PriorityHigh: time.Second},
+		map[string]time.Duration{PriorityHigh: time.Minute},
+	)
+	if got := policies.For(PriorityHigh); got != (RetryPolicy{MaxRetry: 0, BaseDelay: time.Second, MaxDelay: time.Minute}) {
+		t.Errorf("For(high) = %+v, want overrides applied", got)
+	}
+	if got := policies.For(PriorityLow); got != defaults.For(PriorityLow) {
+		t.Errorf("For(low) = %+v, want default %+v for a negative override", got, defaults.For(PriorityLow))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policies := RetryPolicies{
+		PriorityNormal: {MaxRetry: 3, BaseDelay: time.Second, MaxDelay: 2 * time.Second},
+	}
+	payload, err := json.Marshal(DeliverNotificationPayload{Priority: PriorityNormal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := asynq.NewTask(TypeDeliverNotification, payload)
+	failure := errors.New("rate limited (429)")
+
+	// Without Retry-After the priority's backoff applies
+	if got := policies.retryDelay(0, failure, task); got < time.Second || got >= 3*time.Second {
+		t.Errorf("retryDelay without Retry-After = %s, want the backoff", got)
+	}
+
+	// A longer Retry-After wins over the backoff
+	err = &RetryAfterError{Delay: time.Hour, Err: failure}
+	if got := policies.retryDelay(0, err, task); got != time.Hour {
+		t.Errorf("retryDelay with Retry-After = %s, want 1h", got)
+	}
+
+	// A shorter Retry-After doesn't shorten the backoff
+	err = &RetryAfterError{Delay: time.Millisecond, Err: failure}
+	if got := policies.retryDelay(0, err, task); got < time.Second {
+		t.Errorf("retryDelay with short Retry-After = %s, want the backoff", got)
+	}
+}
@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"encoding/json"
+
+	"notifications/internal/repo"
+)
+
+// Segment selects active subscriptions by attribute, e.g. every subscription
+// with locale fr, or whose metadata has role=manager. All set fields must match.
+type Segment struct {
+	Locale   *string           `json:"locale,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Matches reports whether a subscription belongs to the segment
+func (s Segment) Matches(sub repo.DeviceSubscription) bool {
+	if s.Locale != nil && (sub.Locale == nil || *sub.Locale != *s.Locale) {
+		return false
+	}
+	if len(s.Metadata) == 0 {
+		return true
+	}
+
+	var metadata map[string]any
+	if err := json.Unmarshal(sub.Metadata, &metadata); err != nil {
+		return false
+	}
+	for key, want := range s.Metadata {
+		if got, ok := metadata[key].(string); !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// metadataFilter returns the segment's metadata as a JSONB containment filter
+func (s Segment) metadataFilter() (json.RawMessage, error) {
+	if len(s.Metadata) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+	return json.Marshal(s.Metadata)
+}
@@ -3,45 +3,159 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"notifications/internal/callback"
+	"notifications/internal/channel"
+	"notifications/internal/repo"
 )
 
 // Task types
 const (
 	TypeDeliverNotification = "notification:deliver"
+	TypeFanoutNotification  = "notification:fanout"
+	TypeSendCallback        = "notification:callback"
+)
+
+// Notification statuses
+const (
+	NotificationStatusScheduled  = "scheduled"
+	NotificationStatusQueued     = "queued"
+	NotificationStatusSending    = "sending"
+	NotificationStatusSent       = "sent"
+	NotificationStatusPartial    = "partial"
+	NotificationStatusFailed     = "failed"
+	NotificationStatusSuppressed = "suppressed" // Every delivery was skipped
+	NotificationStatusCancelled  = "cancelled"
 )
 
+// Delivery attempt statuses. Retrying, requeued and deferred are
+// intermediate; the others are terminal and count towards the notification's
+// aggregate status.
+const (
+	AttemptStatusDelivered = "delivered"
+	AttemptStatusRetrying  = "retrying"
+	AttemptStatusFailed    = "failed"
+	AttemptStatusPruned    = "pruned"
+	AttemptStatusSkipped   = "skipped"
+	AttemptStatusRequeued  = "requeued"
+	AttemptStatusDeferred  = "deferred"
+)
+
+// Reasons recorded on skipped attempts
+const (
+	AttemptReasonDedupe               = "dedupe"
+	AttemptReasonSubscriptionInactive = "subscription_inactive"
+	AttemptReasonQuietHours           = "quiet_hours"
+	AttemptReasonOptedOut             = "opted_out"
+)
+
+// outboxTaskRetention is how long published outbox tasks are kept after completion
+const outboxTaskRetention = 24 * time.Hour
+
 // Task priorities
 const (
-	PriorityHigh   = "high"
-	PriorityNormal = "normal"
-	PriorityLow    = "low"
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityNormal   = "normal"
+	PriorityLow      = "low"
 )
 
-// DeliverNotificationPayload contains the data needed to deliver a notification
+// DeliverNotificationPayload contains the data needed to deliver a notification.
+// Push deliveries target SubscriptionID; other channels target ContactPointID,
+// except topic webhook deliveries, which target TopicWebhookID and have no UserID.
 type DeliverNotificationPayload struct {
+	NotificationID uuid.UUID  `json:"notification_id"`
+	UserID         string     `json:"user_id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	Priority       string     `json:"priority,omitempty"`
+	Channel        string     `json:"channel,omitempty"` // Empty means push
+	ContactPointID *uuid.UUID `json:"contact_point_id,omitempty"`
+	FallbackID     *uuid.UUID `json:"fallback_id,omitempty"` // Set when sent as a channel fallback
+	TopicWebhookID *uuid.UUID `json:"topic_webhook_id,omitempty"`
+}
+
+// DeliveryChannel returns the channel the delivery is sent on
+func (p DeliverNotificationPayload) DeliveryChannel() string {
+	if p.Channel == "" {
+		return channel.Push
+	}
+	return p.Channel
+}
+
+// TargetID returns the subscription, contact point or topic webhook the
+// delivery is sent to
+func (p DeliverNotificationPayload) TargetID() uuid.UUID {
+	if p.TopicWebhookID != nil {
+		return *p.TopicWebhookID
+	}
+	if p.ContactPointID != nil {
+		return *p.ContactPointID
+	}
+	return p.SubscriptionID
+}
+
+// AttemptParams returns the attempt row fields identifying this delivery;
+// callers fill in the outcome
+func (p DeliverNotificationPayload) AttemptParams() repo.CreateDeliveryAttemptParams {
+	params := repo.CreateDeliveryAttemptParams{
+		NotificationID: p.NotificationID,
+		UserID:         p.UserID,
+		Channel:        p.DeliveryChannel(),
+	}
+	switch {
+	case p.TopicWebhookID != nil:
+		params.TopicWebhookID = pgtype.UUID{Bytes: *p.TopicWebhookID, Valid: true}
+	case p.ContactPointID != nil:
+		params.ContactPointID = pgtype.UUID{Bytes: *p.ContactPointID, Valid: true}
+	default:
+		params.SubscriptionID = pgtype.UUID{Bytes: p.SubscriptionID, Valid: true}
+	}
+	if p.FallbackID != nil {
+		params.FallbackID = pgtype.UUID{Bytes: *p.FallbackID, Valid: true}
+	}
+	return params
+}
+
+// FanoutNotificationPayload identifies a notification whose recipients
+// should be resolved to device subscriptions
+type FanoutNotificationPayload struct {
 	NotificationID uuid.UUID `json:"notification_id"`
-	UserID         string    `json:"user_id"`
-	SubscriptionID uuid.UUID `json:"subscription_id"`
+}
+
+// CallbackPayload is a status callback to a producer. Body is rendered when
+// the event happens, so retries resend the same snapshot. KeyID is the API
+// client that created the notification, whose secret signs the callback.
+type CallbackPayload struct {
+	NotificationID uuid.UUID       `json:"notification_id"`
+	URL            string          `json:"url"`
+	KeyID          string          `json:"key_id,omitempty"`
+	Event          string          `json:"event"`
+	Body           json.RawMessage `json:"body"`
 }
 
 // Client handles enqueuing tasks to Redis/Asynq
 type Client struct {
-	client *asynq.Client
+	client        *asynq.Client
+	retryPolicies RetryPolicies
 }
 
-// NewClient creates a new queue client
-func NewClient(redisAddr string) *Client {
+// NewClient creates a new queue client. Delivery tasks get their retry limit
+// from the policy for their priority.
+func NewClient(redisAddr string, retryPolicies RetryPolicies) *Client {
 	client := asynq.NewClient(asynq.RedisClientOpt{
 		Addr: redisAddr,
 	})
 
 	return &Client{
-		client: client,
+		client:        client,
+		retryPolicies: retryPolicies,
 	}
 }
 
@@ -50,7 +164,10 @@ func (c *Client) Close() error {
 	return c.client.Close()
 }
 
-// EnqueueDeliverNotification enqueues a notification delivery task
+// EnqueueDeliverNotification enqueues a notification delivery task.
+// Task IDs are derived from the notification and subscription, so enqueuing
+// the same delivery twice (e.g. when a fan-out is retried) is a no-op.
+// A non-zero processAt holds the delivery until that time.
 func (c *Client) EnqueueDeliverNotification(
 	ctx context.Context,
 	notificationID uuid.UUID,
@@ -58,13 +175,51 @@ func (c *Client) EnqueueDeliverNotification(
 	subscriptionID uuid.UUID,
 	priority string,
 	ttlSeconds int,
+	processAt time.Time,
 ) error {
 	payload := DeliverNotificationPayload{
 		NotificationID: notificationID,
 		UserID:         userID,
 		SubscriptionID: subscriptionID,
+		Priority:       priority,
 	}
+	return c.EnqueueDelivery(ctx, payload, ttlSeconds, processAt)
+}
+
+// EnqueueDelivery enqueues a delivery task on any channel. Like
+// EnqueueDeliverNotification, the task ID is derived from the notification
+// and the delivery target.
+func (c *Client) EnqueueDelivery(
+	ctx context.Context,
+	payload DeliverNotificationPayload,
+	ttlSeconds int,
+	processAt time.Time,
+) error {
+	taskID := DeliveryTaskID(payload.NotificationID, payload.TargetID())
+	return c.enqueueDelivery(ctx, payload, taskID, ttlSeconds, processAt)
+}
+
+// EnqueueDeferredDelivery re-enqueues a delivery to run at processAt, e.g.
+// once the recipient's quiet hours end. The task ID includes processAt, so it
+// doesn't collide with the delivery task being deferred.
+func (c *Client) EnqueueDeferredDelivery(
+	ctx context.Context,
+	payload DeliverNotificationPayload,
+	processAt time.Time,
+) error {
+	taskID := fmt.Sprintf("%s:%d", DeliveryTaskID(payload.NotificationID, payload.TargetID()), processAt.Unix())
+	return c.enqueueDelivery(ctx, payload, taskID, 0, processAt)
+}
 
+// enqueueDelivery enqueues a delivery task under taskID; a task with the same
+// ID already in the queue makes it a no-op
+func (c *Client) enqueueDelivery(
+	ctx context.Context,
+	payload DeliverNotificationPayload,
+	taskID string,
+	ttlSeconds int,
+	processAt time.Time,
+) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
@@ -74,18 +229,14 @@ func (c *Client) EnqueueDeliverNotification(
 
 	// Configure task options
 	opts := []asynq.Option{
-		asynq.MaxRetry(3),
+		asynq.TaskID(taskID),
+		asynq.MaxRetry(c.retryPolicies.For(payload.Priority).MaxRetry),
 		asynq.Timeout(30 * time.Second),
+		asynq.Queue(queueForPriority(payload.Priority)),
 	}
 
-	// Set priority
-	switch priority {
-	case PriorityHigh:
-		opts = append(opts, asynq.Queue("high"))
-	case PriorityLow:
-		opts = append(opts, asynq.Queue("low"))
-	default:
-		opts = append(opts, asynq.Queue("default"))
+	if !processAt.IsZero() {
+		opts = append(opts, asynq.ProcessAt(processAt))
 	}
 
 	// Set TTL retention time (how long the task info is kept after processing)
@@ -94,12 +245,137 @@ func (c *Client) EnqueueDeliverNotification(
 	}
 
 	// Enqueue the task
-	info, err := c.client.EnqueueContext(ctx, task, opts...)
+	_, err = c.client.EnqueueContext(ctx, task, opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil // Already enqueued
+	}
 	if err != nil {
 		return fmt.Errorf("failed to enqueue task: %w", err)
 	}
 
-	_ = info // Task enqueued successfully
+	return nil
+}
+
+// NewFanoutOutboxMessage builds the outbox row that publishes a notification's
+// fan-out task. It is inserted in the same transaction as the notification.
+// A non-nil processAt defers the fan-out until that time.
+func NewFanoutOutboxMessage(notificationID uuid.UUID, priority string, processAt *time.Time) (repo.CreateOutboxMessageParams, error) {
+	data, err := json.Marshal(FanoutNotificationPayload{NotificationID: notificationID})
+	if err != nil {
+		return repo.CreateOutboxMessageParams{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return repo.CreateOutboxMessageParams{
+		NotificationID: notificationID,
+		TaskType:       TypeFanoutNotification,
+		Payload:        data,
+		Queue:          queueForPriority(priority),
+		ProcessAt:      processAt,
+	}, nil
+}
+
+// NewDeliveryOutboxMessage builds the outbox row that publishes a delivery
+// task, for deliveries that must be queued exactly when the transaction
+// deciding on them commits, such as a triggered fallback's
+func NewDeliveryOutboxMessage(payload DeliverNotificationPayload, processAt time.Time) (repo.CreateOutboxMessageParams, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return repo.CreateOutboxMessageParams{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return repo.CreateOutboxMessageParams{
+		NotificationID: payload.NotificationID,
+		TaskType:       TypeDeliverNotification,
+		Payload:        data,
+		Queue:          queueForPriority(payload.Priority),
+		ProcessAt:      &processAt,
+	}, nil
+}
+
+// NewCallbackOutboxMessage builds the outbox row that publishes a status
+// callback. It is inserted in the same transaction as the status change it
+// reports, so a committed change always gets its callback. keyID is the API
+// client that created the notification, if any.
+func NewCallbackOutboxMessage(callbackURL, keyID string, payload callback.Payload) (repo.CreateOutboxMessageParams, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return repo.CreateOutboxMessageParams{}, fmt.Errorf("failed to marshal callback: %w", err)
+	}
+	data, err := json.Marshal(CallbackPayload{
+		NotificationID: payload.NotificationID,
+		URL:            callbackURL,
+		KeyID:          keyID,
+		Event:          payload.Event,
+		Body:           body,
+	})
+	if err != nil {
+		return repo.CreateOutboxMessageParams{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return repo.CreateOutboxMessageParams{
+		NotificationID: payload.NotificationID,
+		TaskType:       TypeSendCallback,
+		Payload:        data,
+		Queue:          queueForPriority(PriorityLow),
+	}, nil
+}
+
+// PublishOutboxMessage enqueues an outbox row as a task. The outbox row ID is
+// used as the task ID, so publishing the same row twice is a no-op.
+func (c *Client) PublishOutboxMessage(ctx context.Context, msg repo.NotificationOutbox) error {
+	task := asynq.NewTask(msg.TaskType, msg.Payload)
+
+	opts := []asynq.Option{
+		asynq.TaskID(msg.ID.String()),
+		asynq.Queue(msg.Queue),
+		// Keep the task ID reserved after completion so a relay that crashed
+		// before marking the row dispatched cannot publish it again
+		asynq.Retention(outboxTaskRetention),
+	}
+	if msg.ProcessAt != nil {
+		// Scheduled tasks wait in asynq's scheduled set until they are due
+		opts = append(opts, asynq.ProcessAt(*msg.ProcessAt))
+	}
+	if msg.TaskType == TypeFanoutNotification {
+		// Fan-out may touch many recipients, so it gets a longer timeout and more retries
+		opts = append(opts, asynq.MaxRetry(10), asynq.Timeout(5*time.Minute))
+	}
+	if msg.TaskType == TypeSendCallback {
+		opts = append(opts, asynq.MaxRetry(callbackRetryPolicy.MaxRetry))
+	}
+	if msg.TaskType == TypeDeliverNotification {
+		var payload DeliverNotificationPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal delivery payload: %w", err)
+		}
+		opts = append(opts, asynq.MaxRetry(c.retryPolicies.For(payload.Priority).MaxRetry), asynq.Timeout(30*time.Second))
+	}
+
+	_, err := c.client.EnqueueContext(ctx, task, opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil // Already published
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
 
 	return nil
 }
+
+// DeliveryTaskID returns the deterministic task ID for a delivery to a
+// subscription, contact point or topic webhook
+func DeliveryTaskID(notificationID, targetID uuid.UUID) string {
+	return fmt.Sprintf("deliver:%s:%s", notificationID, targetID)
+}
+
+// queueForPriority maps a notification priority to an asynq queue name
+func queueForPriority(priority string) string {
+	switch priority {
+	case PriorityCritical, PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "default"
+	}
+}
@@ -3,58 +3,101 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"notifications/internal/callback"
+	"notifications/internal/channel"
 	"notifications/internal/repo"
-	"notifications/internal/webpush"
+	"notifications/internal/stream"
 )
 
 // Worker processes tasks from Redis/Asynq
 type Worker struct {
-	server *asynq.Server
-	mux    *asynq.ServeMux
-	repo   *repo.Repository
-	sender *webpush.Sender
-	logger *slog.Logger
+	server    *asynq.Server
+	mux       *asynq.ServeMux
+	repo      *repo.Repository
+	senders   map[string]channel.Sender
+	client    *Client
+	publisher *stream.Publisher
+	callbacks *callback.Sender
+	logger    *slog.Logger
+
+	defaultDedupeWindow time.Duration
+	dedupeWindows       map[string]time.Duration
+	defaultTimezone     *time.Location
 }
 
 // WorkerConfig contains configuration for the worker
 type WorkerConfig struct {
-	RedisAddr   string
-	Concurrency int
-	Queues      map[string]int // Queue name to priority weight
+	RedisAddr     string
+	Concurrency   int
+	Queues        map[string]int // Queue name to priority weight
+	RetryPolicies RetryPolicies  // Backoff per notification priority
+
+	// Repeats of a dedupe_key already sent to a user are skipped within
+	// this window; DedupeWindows overrides it per notification type
+	DedupeWindow  time.Duration
+	DedupeWindows map[string]time.Duration
+
+	// Timezone for deliver_local_time and quiet hours when the subscription
+	// or user has none (UTC if nil)
+	DefaultTimezone *time.Location
 }
 
-// NewWorker creates a new worker
+// NewWorker creates a new worker. senders maps each enabled channel to its
+// sender; deliveries on other channels fail. publisher announces new inbox
+// items to open streams. callbacks sends status callbacks to producers.
 func NewWorker(
 	cfg WorkerConfig,
 	repository *repo.Repository,
-	sender *webpush.Sender,
+	senders map[string]channel.Sender,
+	client *Client,
+	publisher *stream.Publisher,
+	callbacks *callback.Sender,
 	logger *slog.Logger,
 ) *Worker {
 	server := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: cfg.RedisAddr},
 		asynq.Config{
-			Concurrency: cfg.Concurrency,
-			Queues:      cfg.Queues,
-			Logger:      &asynqLogger{logger: logger},
+			Concurrency:    cfg.Concurrency,
+			Queues:         cfg.Queues,
+			Logger:         &asynqLogger{logger: logger},
+			RetryDelayFunc: cfg.RetryPolicies.retryDelay,
 		},
 	)
 
+	if cfg.DefaultTimezone == nil {
+		cfg.DefaultTimezone = time.UTC
+	}
+
 	w := &Worker{
-		server: server,
-		mux:    asynq.NewServeMux(),
-		repo:   repository,
-		sender: sender,
-		logger: logger,
+		server:    server,
+		mux:       asynq.NewServeMux(),
+		repo:      repository,
+		senders:   senders,
+		client:    client,
+		publisher: publisher,
+		callbacks: callbacks,
+		logger:    logger,
+
+		defaultDedupeWindow: cfg.DedupeWindow,
+		dedupeWindows:       cfg.DedupeWindows,
+		defaultTimezone:     cfg.DefaultTimezone,
 	}
 
 	// Register task handlers
+	w.mux.HandleFunc(TypeFanoutNotification, w.handleFanoutNotification)
 	w.mux.HandleFunc(TypeDeliverNotification, w.handleDeliverNotification)
+	w.mux.HandleFunc(TypeSendCallback, w.handleSendCallback)
 
 	return w
 }
@@ -71,6 +114,482 @@ func (w *Worker) Stop() {
 	w.server.Shutdown()
 }
 
+// topicMemberBatchSize is how many topic or segment members fan-out loads per query
+const topicMemberBatchSize = 500
+
+// handleFanoutNotification expands a notification's recipients and topic
+// members into one delivery task per active subscription or contact point.
+// Any failure returns an error so the whole fan-out is retried; delivery task
+// IDs are deterministic, so deliveries enqueued by an earlier attempt are not
+// duplicated.
+func (w *Worker) handleFanoutNotification(ctx context.Context, task *asynq.Task) error {
+	var payload FanoutNotificationPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		w.logger.Error("Failed to unmarshal task payload",
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	notif, err := w.repo.GetNotification(ctx, payload.NotificationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deleted since it was queued; nothing to deliver
+		return fmt.Errorf("notification %s not found: %w", payload.NotificationID, asynq.SkipRetry)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	// Scheduled notifications are released for delivery once due; a
+	// cancellation that got there first wins
+	if notif.Status == NotificationStatusScheduled {
+		released, err := w.repo.ReleaseScheduledNotification(ctx, notif.ID)
+		if err != nil {
+			return fmt.Errorf("failed to release scheduled notification: %w", err)
+		}
+		if released == 0 {
+			notif.Status = NotificationStatusCancelled
+		}
+	}
+	if notif.Status == NotificationStatusCancelled {
+		w.logger.Info("Skipping fan-out of cancelled notification",
+			slog.String("notification_id", notif.ID.String()),
+		)
+		return nil
+	}
+
+	recipients, err := w.repo.GetRecipientsByNotification(ctx, notif.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	priority := PriorityNormal
+	if notif.Priority != nil {
+		priority = *notif.Priority
+	}
+	ttl := 3600 // Default 1 hour
+	if notif.TtlSeconds != nil && *notif.TtlSeconds > 0 {
+		ttl = int(*notif.TtlSeconds)
+	}
+
+	policy, err := w.routingPolicy(ctx, notif.Type)
+	if err != nil {
+		return err
+	}
+
+	expectedTotal := 0
+	explicit := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		explicit[recipient.UserID] = true
+		n, err := w.fanoutRecipient(ctx, notif, recipient.UserID, priority, ttl, policy, nil)
+		if err != nil {
+			return err
+		}
+		expectedTotal += n
+	}
+
+	// Topic members are streamed in pages so broadcasts never load every
+	// member at once; explicit recipients who are also members are skipped
+	topicMembers := 0
+	if len(notif.Topics) > 0 {
+		after := ""
+		for {
+			members, err := w.repo.ListTopicMembersPage(ctx, repo.ListTopicMembersPageParams{
+				Topics:      notif.Topics,
+				AfterUserID: after,
+				BatchSize:   topicMemberBatchSize,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list topic members: %w", err)
+			}
+			for _, userID := range members {
+				if explicit[userID] {
+					continue
+				}
+				n, err := w.fanoutRecipient(ctx, notif, userID, priority, ttl, policy, nil)
+				if err != nil {
+					return err
+				}
+				expectedTotal += n
+				topicMembers++
+			}
+			if len(members) < topicMemberBatchSize {
+				break
+			}
+			after = members[len(members)-1]
+		}
+	}
+
+	// Topic webhooks receive one delivery per notification, not one per member
+	topicWebhooks, err := w.fanoutTopicWebhooks(ctx, notif, priority, ttl)
+	if err != nil {
+		return err
+	}
+	expectedTotal += topicWebhooks
+
+	// Segment members only receive the notification on matching subscriptions
+	segmentMembers := 0
+	if len(notif.Segment) > 0 {
+		var segment Segment
+		if err := json.Unmarshal(notif.Segment, &segment); err != nil {
+			return fmt.Errorf("invalid segment: %w: %w", err, asynq.SkipRetry)
+		}
+		metadata, err := segment.metadataFilter()
+		if err != nil {
+			return fmt.Errorf("invalid segment: %w: %w", err, asynq.SkipRetry)
+		}
+
+		after := ""
+		for {
+			members, err := w.repo.ListSegmentUsersPage(ctx, repo.ListSegmentUsersPageParams{
+				Locale:      segment.Locale,
+				Metadata:    metadata,
+				AfterUserID: after,
+				BatchSize:   topicMemberBatchSize,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list segment members: %w", err)
+			}
+			for _, userID := range members {
+				if explicit[userID] {
+					continue
+				}
+				n, err := w.fanoutRecipient(ctx, notif, userID, priority, ttl, policy, segment.Matches)
+				if err != nil {
+					return err
+				}
+				expectedTotal += n
+				segmentMembers++
+			}
+			if len(members) < topicMemberBatchSize {
+				break
+			}
+			after = members[len(members)-1]
+		}
+	}
+
+	// Deliveries may finish before the expected count is known, so roll up
+	// right away as well (this also completes notifications with no subscriptions)
+	expected := int32(expectedTotal)
+	if err := w.repo.StartNotificationDelivery(ctx, repo.StartNotificationDeliveryParams{
+		ID:                 notif.ID,
+		ExpectedDeliveries: &expected,
+	}); err != nil {
+		return fmt.Errorf("failed to update notification status: %w", err)
+	}
+	w.rollupStatus(ctx, notif.ID)
+
+	w.logger.Info("Fanned out notification",
+		slog.String("notification_id", notif.ID.String()),
+		slog.Int("recipients", len(recipients)),
+		slog.Int("topic_members", topicMembers),
+		slog.Int("segment_members", segmentMembers),
+		slog.Int("topic_webhooks", topicWebhooks),
+		slog.Int("deliveries", expectedTotal),
+	)
+
+	return nil
+}
+
+// fanoutRecipient enqueues a delivery for each of the user's targets on the
+// notification's channels: active push subscriptions accepted by match (all of
+// them when match is nil) and active contact points. Unless the recipient is
+// suppressed, their inbox gets a copy whether or not they have any targets;
+// when suppressed, a skipped attempt is recorded for each target instead. A
+// routing policy sets up the recipient's fallback for when push fails. It
+// returns the number of deliveries the notification should expect for this
+// user, skipped ones included; fallback deliveries are counted separately.
+func (w *Worker) fanoutRecipient(
+	ctx context.Context,
+	notif repo.Notification,
+	userID string,
+	priority string,
+	ttl int,
+	policy *repo.NotificationRoutingPolicy,
+	match func(repo.DeviceSubscription) bool,
+) (int, error) {
+	targets, err := w.deliveryTargets(ctx, notif, userID, match)
+	if err != nil {
+		return 0, err
+	}
+	fallback := fallbackChannel(notif, policy)
+
+	reason, err := w.skipReason(ctx, notif, userID)
+	if err != nil {
+		return 0, err
+	}
+	if reason == "" {
+		if err := w.addToInbox(ctx, notif.ID, userID); err != nil {
+			return 0, err
+		}
+	}
+	if len(targets) == 0 && fallback == "" {
+		// Nothing will be delivered, so later sends with the key may pass
+		if reason == "" && notif.DedupeKey != nil {
+			if err := w.repo.ReleaseDedupeClaim(ctx, repo.ReleaseDedupeClaimParams{UserID: userID, NotificationID: notif.ID}); err != nil {
+				return 0, fmt.Errorf("failed to release dedupe key: %w", err)
+			}
+		}
+		return 0, nil
+	}
+
+	// The fallback is created before push deliveries are enqueued, so the
+	// last one to fail always finds it
+	pushTargets := 0
+	for _, target := range targets {
+		if target.payload.ContactPointID == nil {
+			pushTargets++
+		}
+	}
+	if reason == "" && fallback != "" {
+		if _, err := w.repo.CreateFallback(ctx, repo.CreateFallbackParams{
+			NotificationID: notif.ID,
+			UserID:         userID,
+			Channel:        fallback,
+			DelaySeconds:   policy.FallbackDelaySeconds,
+			ExpectedPush:   int32(pushTargets),
+		}); err != nil {
+			return 0, fmt.Errorf("failed to create fallback for user %s: %w", userID, err)
+		}
+	}
+
+	now := time.Now()
+	for _, target := range targets {
+		payload := target.payload
+		payload.NotificationID = notif.ID
+		payload.UserID = userID
+		payload.Priority = priority
+
+		if reason != "" {
+			if err := w.recordAttempt(ctx, payload, AttemptStatusSkipped, nil, nil, 0, "", reason, nil); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		processAt := w.localDeliveryTime(notif, target.timezone, now)
+		if err := w.client.EnqueueDelivery(ctx, payload, ttl, processAt); err != nil {
+			return 0, fmt.Errorf("failed to enqueue delivery for %s target %s: %w", payload.DeliveryChannel(), payload.TargetID(), err)
+		}
+	}
+
+	// Without push subscriptions there is nothing to wait for
+	if reason == "" && fallback != "" && pushTargets == 0 {
+		if err := w.triggerFallback(ctx, notif.ID, userID, priority); err != nil {
+			return 0, err
+		}
+	}
+
+	if reason != "" {
+		w.logger.Info("Skipped recipient",
+			slog.String("notification_id", notif.ID.String()),
+			slog.String("user_id", userID),
+			slog.String("reason", reason),
+		)
+	}
+
+	return len(targets), nil
+}
+
+// fanoutTopicWebhooks enqueues a delivery to each active webhook of the
+// notification's topics when it is sent on the webhook channel. Recipient
+// preferences and quiet hours don't apply to them. It returns the number of
+// deliveries enqueued.
+func (w *Worker) fanoutTopicWebhooks(ctx context.Context, notif repo.Notification, priority string, ttl int) (int, error) {
+	if len(notif.Topics) == 0 || !slices.Contains(notificationChannels(notif), channel.Webhook) {
+		return 0, nil
+	}
+
+	webhooks, err := w.repo.ListActiveTopicWebhooks(ctx, notif.Topics)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list topic webhooks: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		id := webhook.ID
+		payload := DeliverNotificationPayload{
+			NotificationID: notif.ID,
+			Priority:       priority,
+			Channel:        channel.Webhook,
+			TopicWebhookID: &id,
+		}
+		if err := w.client.EnqueueDelivery(ctx, payload, ttl, w.localDeliveryTime(notif, nil, time.Now())); err != nil {
+			return 0, fmt.Errorf("failed to enqueue delivery for topic webhook %s: %w", id, err)
+		}
+	}
+	return len(webhooks), nil
+}
+
+// addToInbox stores the recipient's inbox copy and announces it to their open
+// streams. A copy left by an earlier fan-out attempt isn't announced again.
+func (w *Worker) addToInbox(ctx context.Context, notificationID uuid.UUID, userID string) error {
+	item, err := w.repo.CreateInboxItem(ctx, repo.CreateInboxItemParams{
+		UserID:         userID,
+		NotificationID: notificationID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create inbox item for user %s: %w", userID, err)
+	}
+
+	// Streams that miss the event catch up from the inbox when they reconnect
+	if err := w.publisher.Publish(ctx, stream.Event{
+		InboxItemID:    item.ID,
+		NotificationID: notificationID,
+		UserID:         userID,
+		CreatedAt:      item.CreatedAt,
+	}); err != nil {
+		w.logger.Warn("Failed to publish inbox event",
+			slog.String("notification_id", notificationID.String()),
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil
+}
+
+// deliveryTarget is a subscription or contact point a recipient is reached at
+type deliveryTarget struct {
+	payload  DeliverNotificationPayload // Channel and target fields only
+	timezone *string
+}
+
+// deliveryTargets lists the user's active targets on each of the
+// notification's channels
+func (w *Worker) deliveryTargets(
+	ctx context.Context,
+	notif repo.Notification,
+	userID string,
+	match func(repo.DeviceSubscription) bool,
+) ([]deliveryTarget, error) {
+	var targets []deliveryTarget
+	for _, name := range notificationChannels(notif) {
+		if name == channel.Push {
+			subscriptions, err := w.repo.ListActiveDeviceSubscriptionsByUser(ctx, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list subscriptions for user %s: %w", userID, err)
+			}
+			for _, sub := range subscriptions {
+				if match != nil && !match(sub) {
+					continue
+				}
+				targets = append(targets, deliveryTarget{
+					payload:  DeliverNotificationPayload{SubscriptionID: sub.ID},
+					timezone: sub.Timezone,
+				})
+			}
+			continue
+		}
+
+		contacts, err := w.repo.ListActiveContactPointsByUser(ctx, repo.ListActiveContactPointsByUserParams{
+			UserID:  userID,
+			Channel: name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s contact points for user %s: %w", name, userID, err)
+		}
+		for _, contact := range contacts {
+			id := contact.ID
+			targets = append(targets, deliveryTarget{
+				payload: DeliverNotificationPayload{Channel: name, ContactPointID: &id},
+			})
+		}
+	}
+	return targets, nil
+}
+
+// notificationChannels returns the channels a notification is sent on
+func notificationChannels(notif repo.Notification) []string {
+	if len(notif.Channels) == 0 {
+		return []string{channel.Push}
+	}
+	return notif.Channels
+}
+
+// localDeliveryTime returns the next occurrence of the notification's
+// deliver_local_time in the target's timezone (or the default timezone), or
+// the zero time when the notification should be delivered right away.
+func (w *Worker) localDeliveryTime(notif repo.Notification, timezone *string, now time.Time) time.Time {
+	if notif.DeliverLocalTime == nil {
+		return time.Time{}
+	}
+	clock, err := time.Parse("15:04", *notif.DeliverLocalTime)
+	if err != nil {
+		w.logger.Warn("Invalid deliver_local_time, delivering now",
+			slog.String("notification_id", notif.ID.String()),
+			slog.String("deliver_local_time", *notif.DeliverLocalTime),
+		)
+		return time.Time{}
+	}
+
+	loc := w.defaultTimezone
+	if timezone != nil && *timezone != "" {
+		if targetLoc, err := time.LoadLocation(*timezone); err == nil {
+			loc = targetLoc
+		} else {
+			w.logger.Warn("Invalid subscription timezone, using default",
+				slog.String("notification_id", notif.ID.String()),
+				slog.String("timezone", *timezone),
+			)
+		}
+	}
+
+	local := now.In(loc)
+	at := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	if at.Before(local) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// skipReason returns why a recipient should not receive the notification,
+// or "" when it should be delivered.
+func (w *Worker) skipReason(ctx context.Context, notif repo.Notification, userID string) (string, error) {
+	// Suppress types the user opted out of in their preferences
+	prefs, err := w.repo.ListPreferencesByUser(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get preferences: %w", err)
+	}
+	if !PreferenceAllows(prefs, notif.Type) {
+		return AttemptReasonOptedOut, nil
+	}
+
+	// Suppress repeats of a dedupe_key delivered to the user within the
+	// window. The key is claimed rather than looked up, so two notifications
+	// with the same key fanned out at the same time can't both pass. The claim
+	// is renewed for the window when a delivery succeeds, and released if the
+	// recipient ends up receiving nothing.
+	if notif.DedupeKey != nil && *notif.DedupeKey != "" {
+		if window := w.dedupeWindow(notif.Type); window > 0 {
+			claimed, err := w.repo.ClaimDedupeKey(ctx, repo.ClaimDedupeKeyParams{
+				UserID:         userID,
+				DedupeKey:      *notif.DedupeKey,
+				NotificationID: notif.ID,
+				ExpiresAt:      time.Now().Add(window),
+				WindowSeconds:  int32(window / time.Second),
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to claim dedupe key: %w", err)
+			}
+			if claimed == 0 {
+				return AttemptReasonDedupe, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// dedupeWindow returns the suppression window for a notification type
+func (w *Worker) dedupeWindow(notificationType string) time.Duration {
+	if window, ok := w.dedupeWindows[notificationType]; ok {
+		return window
+	}
+	return w.defaultDedupeWindow
+}
+
 // handleDeliverNotification processes a notification delivery task
 func (w *Worker) handleDeliverNotification(ctx context.Context, task *asynq.Task) error {
 	// Parse the payload
@@ -85,17 +604,42 @@ func (w *Worker) handleDeliverNotification(ctx context.Context, task *asynq.Task
 	w.logger.Info("Processing notification delivery",
 		slog.String("notification_id", payload.NotificationID.String()),
 		slog.String("user_id", payload.UserID),
-		slog.String("subscription_id", payload.SubscriptionID.String()),
+		slog.String("channel", payload.DeliveryChannel()),
+		slog.String("target_id", payload.TargetID().String()),
 	)
 
-	// Retry count will be tracked manually through task retries
-	retryCount := 0
+	// Intermediate failures are recorded as retrying; only the last retry is terminal
+	retryCount, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	failedStatus := AttemptStatusRetrying
+	if retryCount >= maxRetry {
+		failedStatus = AttemptStatusFailed
+	}
+
+	// Non-critical deliveries wait out the recipient's quiet hours
+	if payload.Priority != PriorityCritical && payload.UserID != "" {
+		until, err := w.quietHoursDeferral(ctx, payload.UserID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !until.IsZero() {
+			return w.deferDelivery(ctx, payload, until, retryCount)
+		}
+	}
+
+	sender, ok := w.senders[payload.DeliveryChannel()]
+	if !ok {
+		errMsg := fmt.Sprintf("channel %s is not enabled", payload.DeliveryChannel())
+		_ = w.recordAttempt(ctx, payload, AttemptStatusFailed, nil, nil, retryCount, errMsg, "", nil)
+		w.rollupStatus(ctx, payload.NotificationID)
+		return fmt.Errorf("%s: %w", errMsg, asynq.SkipRetry)
+	}
 
 	// Send the notification
-	result, err := w.sender.SendNotification(
+	result, err := sender.SendNotification(
 		ctx,
 		payload.NotificationID,
-		payload.SubscriptionID,
+		payload.TargetID(),
 		payload.UserID,
 	)
 	if err != nil {
@@ -104,14 +648,28 @@ func (w *Worker) handleDeliverNotification(ctx context.Context, task *asynq.Task
 			slog.String("error", err.Error()),
 		)
 		// Record failed attempt
-		_ = w.recordAttempt(ctx, payload, "failed", nil, nil, retryCount, err.Error())
+		_ = w.recordAttempt(ctx, payload, failedStatus, nil, nil, retryCount, err.Error(), "", nil)
+		w.rollupStatus(ctx, payload.NotificationID)
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
 	// Record the delivery attempt
-	status := "delivered"
-	if !result.Success {
-		status = "failed"
+	var status, reason string
+	var class FailureClass
+	switch {
+	case result.Success:
+		status = AttemptStatusDelivered
+	case result.Skipped:
+		status = AttemptStatusSkipped
+		reason = AttemptReasonSubscriptionInactive
+	case result.ShouldPrune:
+		status = AttemptStatusPruned
+	default:
+		class = ClassifyResult(result)
+		status = failedStatus
+		if class == FailurePermanent {
+			status = AttemptStatusFailed
+		}
 	}
 
 	httpStatus := result.HTTPStatus
@@ -126,6 +684,8 @@ func (w *Worker) handleDeliverNotification(ctx context.Context, task *asynq.Task
 		&latencyMs,
 		retryCount,
 		errorMsg,
+		reason,
+		result,
 	); err != nil {
 		w.logger.Error("Failed to record delivery attempt",
 			slog.String("notification_id", payload.NotificationID.String()),
@@ -134,7 +694,7 @@ func (w *Worker) handleDeliverNotification(ctx context.Context, task *asynq.Task
 	}
 
 	// Prune subscription if needed (mark as inactive)
-	if result.ShouldPrune {
+	if result.ShouldPrune && payload.DeliveryChannel() == channel.Push {
 		w.logger.Info("Deactivating subscription",
 			slog.String("subscription_id", payload.SubscriptionID.String()),
 			slog.String("reason", result.Error),
@@ -147,15 +707,76 @@ func (w *Worker) handleDeliverNotification(ctx context.Context, task *asynq.Task
 		}
 	}
 
-	// If the delivery failed but shouldn't be pruned, return an error to trigger retry
-	if !result.Success && !result.ShouldPrune {
-		return fmt.Errorf("delivery failed: %s", result.Error)
+	w.rollupStatus(ctx, payload.NotificationID)
+
+	// Failed deliveries return an error so asynq retries them per the retry policy
+	if status != AttemptStatusRetrying && status != AttemptStatusFailed {
+		return nil
+	}
+	deliveryErr := fmt.Errorf("delivery failed: %s", result.Error)
+	switch class {
+	case FailurePermanent:
+		return fmt.Errorf("%w: %w", deliveryErr, asynq.SkipRetry)
+	case FailureRateLimited:
+		return &RetryAfterError{Delay: result.RetryAfter, Err: deliveryErr}
+	default:
+		return deliveryErr
 	}
+}
 
+// deferDelivery re-enqueues a delivery for until and records a deferred
+// attempt. The notification stays pending until the deferred delivery runs.
+func (w *Worker) deferDelivery(ctx context.Context, payload DeliverNotificationPayload, until time.Time, retryCount int) error {
+	if err := w.client.EnqueueDeferredDelivery(ctx, payload, until); err != nil {
+		return fmt.Errorf("failed to defer delivery: %w", err)
+	}
+
+	if err := w.recordAttempt(ctx, payload, AttemptStatusDeferred, nil, nil, retryCount, "", AttemptReasonQuietHours, nil); err != nil {
+		w.logger.Error("Failed to record delivery attempt",
+			slog.String("notification_id", payload.NotificationID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	w.logger.Info("Deferred delivery for quiet hours",
+		slog.String("notification_id", payload.NotificationID.String()),
+		slog.String("user_id", payload.UserID),
+		slog.Time("until", until),
+	)
 	return nil
 }
 
-// recordAttempt records a delivery attempt in the database
+// rollupStatus completes the notification's aggregate status once every
+// expected delivery has a terminal outcome. It is a no-op until then, and
+// only the first caller to observe completion updates the row (and queues
+// the completion callback).
+func (w *Worker) rollupStatus(ctx context.Context, notificationID uuid.UUID) {
+	var completed bool
+	err := w.repo.WithTx(ctx, func(q *repo.Queries) error {
+		var err error
+		completed, err = RollupNotificationStatus(ctx, q, notificationID)
+		return err
+	})
+	if err != nil {
+		w.logger.Error("Failed to roll up notification status",
+			slog.String("notification_id", notificationID.String()),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if completed {
+		w.logger.Info("Notification delivery completed",
+			slog.String("notification_id", notificationID.String()),
+		)
+	}
+}
+
+// recordAttempt records a delivery attempt in the database, with its
+// callback if the notification asks for one. result is the outcome of an
+// actual send, if any; the template version it was rendered from is recorded.
+// A push attempt that didn't reach the device triggers the recipient's
+// fallback in the same transaction once every push delivery to them has
+// failed, was pruned or skipped.
 func (w *Worker) recordAttempt(
 	ctx context.Context,
 	payload DeliverNotificationPayload,
@@ -164,6 +785,8 @@ func (w *Worker) recordAttempt(
 	latencyMs *int32,
 	retryCount int,
 	errorMsg string,
+	reason string,
+	result *channel.DeliveryResult,
 ) error {
 	var httpStatusInt *int32
 	if httpStatus != nil {
@@ -176,20 +799,48 @@ func (w *Worker) recordAttempt(
 		errorStr = &errorMsg
 	}
 
+	var reasonStr *string
+	if reason != "" {
+		reasonStr = &reason
+	}
+
 	retryCountInt := int32(retryCount)
 
-	params := repo.CreateDeliveryAttemptParams{
-		NotificationID: payload.NotificationID,
-		SubscriptionID: pgtype.UUID{Bytes: payload.SubscriptionID, Valid: true},
-		UserID:         payload.UserID,
-		Status:         status,
-		HttpStatus:     httpStatusInt,
-		LatencyMs:      latencyMs,
-		Error:          errorStr,
-		RetryCount:     &retryCountInt,
+	params := payload.AttemptParams()
+	params.Status = status
+	params.HttpStatus = httpStatusInt
+	params.LatencyMs = latencyMs
+	params.Error = errorStr
+	params.RetryCount = &retryCountInt
+	params.Reason = reasonStr
+	if result != nil && result.TemplateID != nil {
+		params.TemplateID = pgtype.UUID{Bytes: *result.TemplateID, Valid: true}
+		params.TemplateVersion = result.TemplateVersion
 	}
 
-	if _, err := w.repo.CreateDeliveryAttempt(ctx, params); err != nil {
+	err := w.repo.WithTx(ctx, func(q *repo.Queries) error {
+		var fallback *repo.NotificationFallback
+		if payload.DeliveryChannel() == channel.Push && payload.UserID != "" && isFallbackOutcome(status) {
+			var err error
+			if fallback, err = lockPendingFallback(ctx, q, payload.NotificationID, payload.UserID); err != nil {
+				return err
+			}
+		}
+		if _, err := CreateDeliveryAttempt(ctx, q, params); err != nil {
+			return err
+		}
+		// The dedupe window runs from the recipient's delivery
+		if status == AttemptStatusDelivered && payload.UserID != "" {
+			if err := q.ExtendDedupeClaim(ctx, repo.ExtendDedupeClaimParams{UserID: payload.UserID, NotificationID: payload.NotificationID}); err != nil {
+				return fmt.Errorf("failed to extend dedupe claim: %w", err)
+			}
+		}
+		if fallback != nil {
+			return w.triggerFallbackTx(ctx, q, *fallback, payload.Priority)
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to create delivery attempt: %w", err)
 	}
 
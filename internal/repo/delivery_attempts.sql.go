@@ -13,6 +13,47 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimDedupeKey = `-- name: ClaimDedupeKey :execrows
+INSERT INTO notification_dedupe_claims (
+  user_id,
+  dedupe_key,
+  notification_id,
+  expires_at,
+  window_seconds
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, dedupe_key) DO UPDATE
+SET
+  notification_id = EXCLUDED.notification_id,
+  expires_at = EXCLUDED.expires_at,
+  window_seconds = EXCLUDED.window_seconds
+WHERE notification_dedupe_claims.expires_at <= now()
+  OR notification_dedupe_claims.notification_id = EXCLUDED.notification_id
+`
+
+type ClaimDedupeKeyParams struct {
+	UserID         string    `json:"user_id"`
+	DedupeKey      string    `json:"dedupe_key"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	WindowSeconds  int32     `json:"window_seconds"`
+}
+
+func (q *Queries) ClaimDedupeKey(ctx context.Context, arg ClaimDedupeKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimDedupeKey,
+		arg.UserID,
+		arg.DedupeKey,
+		arg.NotificationID,
+		arg.ExpiresAt,
+		arg.WindowSeconds,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const countDeliveryAttemptsByNotification = `-- name: CountDeliveryAttemptsByNotification :one
 SELECT COUNT(*) FROM notification_attempts
 WHERE notification_id = $1
@@ -46,22 +87,36 @@ INSERT INTO notification_attempts (
   http_status,
   latency_ms,
   error,
-  retry_count
+  retry_count,
+  reason,
+  channel,
+  contact_point_id,
+  fallback_id,
+  topic_webhook_id,
+  template_id,
+  template_version
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 )
-RETURNING id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at
+RETURNING id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version
 `
 
 type CreateDeliveryAttemptParams struct {
-	NotificationID uuid.UUID   `json:"notification_id"`
-	SubscriptionID pgtype.UUID `json:"subscription_id"`
-	UserID         string      `json:"user_id"`
-	Status         string      `json:"status"`
-	HttpStatus     *int32      `json:"http_status"`
-	LatencyMs      *int32      `json:"latency_ms"`
-	Error          *string     `json:"error"`
-	RetryCount     *int32      `json:"retry_count"`
+	NotificationID  uuid.UUID   `json:"notification_id"`
+	SubscriptionID  pgtype.UUID `json:"subscription_id"`
+	UserID          string      `json:"user_id"`
+	Status          string      `json:"status"`
+	HttpStatus      *int32      `json:"http_status"`
+	LatencyMs       *int32      `json:"latency_ms"`
+	Error           *string     `json:"error"`
+	RetryCount      *int32      `json:"retry_count"`
+	Reason          *string     `json:"reason"`
+	Channel         string      `json:"channel"`
+	ContactPointID  pgtype.UUID `json:"contact_point_id"`
+	FallbackID      pgtype.UUID `json:"fallback_id"`
+	TopicWebhookID  pgtype.UUID `json:"topic_webhook_id"`
+	TemplateID      pgtype.UUID `json:"template_id"`
+	TemplateVersion *int32      `json:"template_version"`
 }
 
 func (q *Queries) CreateDeliveryAttempt(ctx context.Context, arg CreateDeliveryAttemptParams) (NotificationAttempt, error) {
@@ -74,6 +129,13 @@ func (q *Queries) CreateDeliveryAttempt(ctx context.Context, arg CreateDeliveryA
 		arg.LatencyMs,
 		arg.Error,
 		arg.RetryCount,
+		arg.Reason,
+		arg.Channel,
+		arg.ContactPointID,
+		arg.FallbackID,
+		arg.TopicWebhookID,
+		arg.TemplateID,
+		arg.TemplateVersion,
 	)
 	var i NotificationAttempt
 	err := row.Scan(
@@ -88,6 +150,13 @@ func (q *Queries) CreateDeliveryAttempt(ctx context.Context, arg CreateDeliveryA
 		&i.RetryCount,
 		&i.Pruned,
 		&i.CreatedAt,
+		&i.Reason,
+		&i.Channel,
+		&i.ContactPointID,
+		&i.FallbackID,
+		&i.TopicWebhookID,
+		&i.TemplateID,
+		&i.TemplateVersion,
 	)
 	return i, err
 }
@@ -102,8 +171,24 @@ func (q *Queries) DeleteOldAttempts(ctx context.Context, createdAt time.Time) er
 	return err
 }
 
+const extendDedupeClaim = `-- name: ExtendDedupeClaim :exec
+UPDATE notification_dedupe_claims
+SET expires_at = now() + make_interval(secs => window_seconds)
+WHERE user_id = $1 AND notification_id = $2
+`
+
+type ExtendDedupeClaimParams struct {
+	UserID         string    `json:"user_id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+}
+
+func (q *Queries) ExtendDedupeClaim(ctx context.Context, arg ExtendDedupeClaimParams) error {
+	_, err := q.db.Exec(ctx, extendDedupeClaim, arg.UserID, arg.NotificationID)
+	return err
+}
+
 const findFailedAttemptsBySubscription = `-- name: FindFailedAttemptsBySubscription :many
-SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at FROM notification_attempts
+SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version FROM notification_attempts
 WHERE subscription_id = $1
   AND status = 'failed'
   AND created_at >= $2
@@ -136,6 +221,13 @@ func (q *Queries) FindFailedAttemptsBySubscription(ctx context.Context, arg Find
 			&i.RetryCount,
 			&i.Pruned,
 			&i.CreatedAt,
+			&i.Reason,
+			&i.Channel,
+			&i.ContactPointID,
+			&i.FallbackID,
+			&i.TopicWebhookID,
+			&i.TemplateID,
+			&i.TemplateVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -148,7 +240,7 @@ func (q *Queries) FindFailedAttemptsBySubscription(ctx context.Context, arg Find
 }
 
 const getDeliveryAttempt = `-- name: GetDeliveryAttempt :one
-SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at FROM notification_attempts
+SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version FROM notification_attempts
 WHERE id = $1 LIMIT 1
 `
 
@@ -167,6 +259,13 @@ func (q *Queries) GetDeliveryAttempt(ctx context.Context, id uuid.UUID) (Notific
 		&i.RetryCount,
 		&i.Pruned,
 		&i.CreatedAt,
+		&i.Reason,
+		&i.Channel,
+		&i.ContactPointID,
+		&i.FallbackID,
+		&i.TopicWebhookID,
+		&i.TemplateID,
+		&i.TemplateVersion,
 	)
 	return i, err
 }
@@ -200,8 +299,48 @@ func (q *Queries) GetDeliveryStats(ctx context.Context, createdAt time.Time) (Ge
 	return i, err
 }
 
+const getNotificationDeliveryCounts = `-- name: GetNotificationDeliveryCounts :one
+WITH latest AS (
+  SELECT DISTINCT ON (COALESCE(subscription_id, contact_point_id, topic_webhook_id)) status
+  FROM notification_attempts
+  WHERE notification_id = $1
+  ORDER BY COALESCE(subscription_id, contact_point_id, topic_webhook_id), created_at DESC
+)
+SELECT
+  COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
+  COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+  COUNT(*) FILTER (WHERE status = 'pruned') AS pruned,
+  COUNT(*) FILTER (WHERE status = 'skipped') AS skipped,
+  COUNT(*) FILTER (WHERE status = 'retrying') AS retrying,
+  COUNT(*) FILTER (WHERE status = 'deferred') AS deferred
+FROM latest
+`
+
+type GetNotificationDeliveryCountsRow struct {
+	Delivered int64 `json:"delivered"`
+	Failed    int64 `json:"failed"`
+	Pruned    int64 `json:"pruned"`
+	Skipped   int64 `json:"skipped"`
+	Retrying  int64 `json:"retrying"`
+	Deferred  int64 `json:"deferred"`
+}
+
+func (q *Queries) GetNotificationDeliveryCounts(ctx context.Context, notificationID uuid.UUID) (GetNotificationDeliveryCountsRow, error) {
+	row := q.db.QueryRow(ctx, getNotificationDeliveryCounts, notificationID)
+	var i GetNotificationDeliveryCountsRow
+	err := row.Scan(
+		&i.Delivered,
+		&i.Failed,
+		&i.Pruned,
+		&i.Skipped,
+		&i.Retrying,
+		&i.Deferred,
+	)
+	return i, err
+}
+
 const listDeliveryAttemptsByNotification = `-- name: ListDeliveryAttemptsByNotification :many
-SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at FROM notification_attempts
+SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version FROM notification_attempts
 WHERE notification_id = $1
 ORDER BY created_at DESC
 `
@@ -227,6 +366,13 @@ func (q *Queries) ListDeliveryAttemptsByNotification(ctx context.Context, notifi
 			&i.RetryCount,
 			&i.Pruned,
 			&i.CreatedAt,
+			&i.Reason,
+			&i.Channel,
+			&i.ContactPointID,
+			&i.FallbackID,
+			&i.TopicWebhookID,
+			&i.TemplateID,
+			&i.TemplateVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -239,7 +385,7 @@ func (q *Queries) ListDeliveryAttemptsByNotification(ctx context.Context, notifi
 }
 
 const listDeliveryAttemptsByStatus = `-- name: ListDeliveryAttemptsByStatus :many
-SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at FROM notification_attempts
+SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version FROM notification_attempts
 WHERE status = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -272,6 +418,13 @@ func (q *Queries) ListDeliveryAttemptsByStatus(ctx context.Context, arg ListDeli
 			&i.RetryCount,
 			&i.Pruned,
 			&i.CreatedAt,
+			&i.Reason,
+			&i.Channel,
+			&i.ContactPointID,
+			&i.FallbackID,
+			&i.TopicWebhookID,
+			&i.TemplateID,
+			&i.TemplateVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -284,7 +437,7 @@ func (q *Queries) ListDeliveryAttemptsByStatus(ctx context.Context, arg ListDeli
 }
 
 const listDeliveryAttemptsBySubscription = `-- name: ListDeliveryAttemptsBySubscription :many
-SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at FROM notification_attempts
+SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version FROM notification_attempts
 WHERE subscription_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -317,6 +470,13 @@ func (q *Queries) ListDeliveryAttemptsBySubscription(ctx context.Context, arg Li
 			&i.RetryCount,
 			&i.Pruned,
 			&i.CreatedAt,
+			&i.Reason,
+			&i.Channel,
+			&i.ContactPointID,
+			&i.FallbackID,
+			&i.TopicWebhookID,
+			&i.TemplateID,
+			&i.TemplateVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -329,7 +489,7 @@ func (q *Queries) ListDeliveryAttemptsBySubscription(ctx context.Context, arg Li
 }
 
 const listDeliveryAttemptsByUser = `-- name: ListDeliveryAttemptsByUser :many
-SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at FROM notification_attempts
+SELECT id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version FROM notification_attempts
 WHERE user_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -362,6 +522,13 @@ func (q *Queries) ListDeliveryAttemptsByUser(ctx context.Context, arg ListDelive
 			&i.RetryCount,
 			&i.Pruned,
 			&i.CreatedAt,
+			&i.Reason,
+			&i.Channel,
+			&i.ContactPointID,
+			&i.FallbackID,
+			&i.TopicWebhookID,
+			&i.TemplateID,
+			&i.TemplateVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -384,6 +551,39 @@ func (q *Queries) MarkSubscriptionAsPruned(ctx context.Context, subscriptionID p
 	return err
 }
 
+const releaseDedupeClaim = `-- name: ReleaseDedupeClaim :exec
+DELETE FROM notification_dedupe_claims
+WHERE user_id = $1 AND notification_id = $2
+`
+
+type ReleaseDedupeClaimParams struct {
+	UserID         string    `json:"user_id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+}
+
+func (q *Queries) ReleaseDedupeClaim(ctx context.Context, arg ReleaseDedupeClaimParams) error {
+	_, err := q.db.Exec(ctx, releaseDedupeClaim, arg.UserID, arg.NotificationID)
+	return err
+}
+
+const releaseUndeliveredDedupeClaims = `-- name: ReleaseUndeliveredDedupeClaims :execrows
+DELETE FROM notification_dedupe_claims c
+WHERE c.notification_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM notification_attempts a
+    WHERE a.notification_id = c.notification_id AND a.user_id = c.user_id
+      AND a.status = 'delivered'
+  )
+`
+
+func (q *Queries) ReleaseUndeliveredDedupeClaims(ctx context.Context, notificationID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseUndeliveredDedupeClaims, notificationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateDeliveryAttemptStatus = `-- name: UpdateDeliveryAttemptStatus :one
 UPDATE notification_attempts
 SET
@@ -393,7 +593,7 @@ SET
   error = COALESCE($4, error),
   retry_count = COALESCE($5, retry_count)
 WHERE id = $6
-RETURNING id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at
+RETURNING id, notification_id, subscription_id, user_id, status, http_status, latency_ms, error, retry_count, pruned, created_at, reason, channel, contact_point_id, fallback_id, topic_webhook_id, template_id, template_version
 `
 
 type UpdateDeliveryAttemptStatusParams struct {
@@ -427,6 +627,13 @@ func (q *Queries) UpdateDeliveryAttemptStatus(ctx context.Context, arg UpdateDel
 		&i.RetryCount,
 		&i.Pruned,
 		&i.CreatedAt,
+		&i.Reason,
+		&i.Channel,
+		&i.ContactPointID,
+		&i.FallbackID,
+		&i.TopicWebhookID,
+		&i.TemplateID,
+		&i.TemplateVersion,
 	)
 	return i, err
 }
@@ -7,6 +7,7 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -33,22 +34,24 @@ INSERT INTO device_subscriptions (
   device_id,
   user_agent,
   locale,
-  timezone
+  timezone,
+  metadata
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
-RETURNING id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at
+RETURNING id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata
 `
 
 type CreateDeviceSubscriptionParams struct {
-	UserID    string  `json:"user_id"`
-	Endpoint  string  `json:"endpoint"`
-	P256dh    string  `json:"p256dh"`
-	Auth      string  `json:"auth"`
-	DeviceID  *string `json:"device_id"`
-	UserAgent *string `json:"user_agent"`
-	Locale    *string `json:"locale"`
-	Timezone  *string `json:"timezone"`
+	UserID    string          `json:"user_id"`
+	Endpoint  string          `json:"endpoint"`
+	P256dh    string          `json:"p256dh"`
+	Auth      string          `json:"auth"`
+	DeviceID  *string         `json:"device_id"`
+	UserAgent *string         `json:"user_agent"`
+	Locale    *string         `json:"locale"`
+	Timezone  *string         `json:"timezone"`
+	Metadata  json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateDeviceSubscription(ctx context.Context, arg CreateDeviceSubscriptionParams) (DeviceSubscription, error) {
@@ -61,6 +64,7 @@ func (q *Queries) CreateDeviceSubscription(ctx context.Context, arg CreateDevice
 		arg.UserAgent,
 		arg.Locale,
 		arg.Timezone,
+		arg.Metadata,
 	)
 	var i DeviceSubscription
 	err := row.Scan(
@@ -76,6 +80,7 @@ func (q *Queries) CreateDeviceSubscription(ctx context.Context, arg CreateDevice
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Metadata,
 	)
 	return i, err
 }
@@ -112,7 +117,7 @@ func (q *Queries) DeleteDeviceSubscriptionByEndpoint(ctx context.Context, endpoi
 }
 
 const findStaleSubscriptions = `-- name: FindStaleSubscriptions :many
-SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at FROM device_subscriptions
+SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata FROM device_subscriptions
 WHERE is_active = true
   AND updated_at < $1
 ORDER BY updated_at ASC
@@ -146,6 +151,7 @@ func (q *Queries) FindStaleSubscriptions(ctx context.Context, arg FindStaleSubsc
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -158,7 +164,7 @@ func (q *Queries) FindStaleSubscriptions(ctx context.Context, arg FindStaleSubsc
 }
 
 const getDeviceSubscription = `-- name: GetDeviceSubscription :one
-SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at FROM device_subscriptions
+SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata FROM device_subscriptions
 WHERE id = $1 LIMIT 1
 `
 
@@ -178,12 +184,13 @@ func (q *Queries) GetDeviceSubscription(ctx context.Context, id uuid.UUID) (Devi
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getDeviceSubscriptionByEndpoint = `-- name: GetDeviceSubscriptionByEndpoint :one
-SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at FROM device_subscriptions
+SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata FROM device_subscriptions
 WHERE endpoint = $1 LIMIT 1
 `
 
@@ -203,12 +210,13 @@ func (q *Queries) GetDeviceSubscriptionByEndpoint(ctx context.Context, endpoint
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const listActiveDeviceSubscriptionsByUser = `-- name: ListActiveDeviceSubscriptionsByUser :many
-SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at FROM device_subscriptions
+SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata FROM device_subscriptions
 WHERE user_id = $1 AND is_active = true
 ORDER BY created_at DESC
 `
@@ -235,6 +243,7 @@ func (q *Queries) ListActiveDeviceSubscriptionsByUser(ctx context.Context, userI
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -247,7 +256,7 @@ func (q *Queries) ListActiveDeviceSubscriptionsByUser(ctx context.Context, userI
 }
 
 const listDeviceSubscriptionsByUser = `-- name: ListDeviceSubscriptionsByUser :many
-SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at FROM device_subscriptions
+SELECT id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata FROM device_subscriptions
 WHERE user_id = $1
 ORDER BY created_at DESC
 `
@@ -274,6 +283,7 @@ func (q *Queries) ListDeviceSubscriptionsByUser(ctx context.Context, userID stri
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -285,6 +295,48 @@ func (q *Queries) ListDeviceSubscriptionsByUser(ctx context.Context, userID stri
 	return items, nil
 }
 
+const listSegmentUsersPage = `-- name: ListSegmentUsersPage :many
+SELECT DISTINCT user_id FROM device_subscriptions
+WHERE is_active = true
+  AND ($1::text IS NULL OR locale = $1::text)
+  AND metadata @> $2::jsonb
+  AND user_id > $3
+ORDER BY user_id
+LIMIT $4
+`
+
+type ListSegmentUsersPageParams struct {
+	Locale      *string         `json:"locale"`
+	Metadata    json.RawMessage `json:"metadata"`
+	AfterUserID string          `json:"after_user_id"`
+	BatchSize   int32           `json:"batch_size"`
+}
+
+func (q *Queries) ListSegmentUsersPage(ctx context.Context, arg ListSegmentUsersPageParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listSegmentUsersPage,
+		arg.Locale,
+		arg.Metadata,
+		arg.AfterUserID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateDeviceSubscription = `-- name: UpdateDeviceSubscription :one
 UPDATE device_subscriptions
 SET
@@ -297,7 +349,7 @@ SET
   is_active = COALESCE($7, is_active),
   updated_at = now()
 WHERE id = $8
-RETURNING id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at
+RETURNING id, user_id, endpoint, p256dh, auth, device_id, user_agent, locale, timezone, is_active, created_at, updated_at, metadata
 `
 
 type UpdateDeviceSubscriptionParams struct {
@@ -336,6 +388,7 @@ func (q *Queries) UpdateDeviceSubscription(ctx context.Context, arg UpdateDevice
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Metadata,
 	)
 	return i, err
 }
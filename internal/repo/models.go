@@ -12,53 +12,221 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiClient struct {
+	ID          uuid.UUID `json:"id"`
+	KeyID       string    `json:"key_id"`
+	Name        string    `json:"name"`
+	Scopes      []string  `json:"scopes"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CallbackUrl *string   `json:"callback_url"`
+}
+
+type ApiClientSecret struct {
+	ID               uuid.UUID `json:"id"`
+	ClientID         uuid.UUID `json:"client_id"`
+	SecretCiphertext string    `json:"secret_ciphertext"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
 type DeviceSubscription struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    string    `json:"user_id"`
-	Endpoint  string    `json:"endpoint"`
-	P256dh    string    `json:"p256dh"`
-	Auth      string    `json:"auth"`
-	DeviceID  *string   `json:"device_id"`
-	UserAgent *string   `json:"user_agent"`
-	Locale    *string   `json:"locale"`
-	Timezone  *string   `json:"timezone"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID       `json:"id"`
+	UserID    string          `json:"user_id"`
+	Endpoint  string          `json:"endpoint"`
+	P256dh    string          `json:"p256dh"`
+	Auth      string          `json:"auth"`
+	DeviceID  *string         `json:"device_id"`
+	UserAgent *string         `json:"user_agent"`
+	Locale    *string         `json:"locale"`
+	Timezone  *string         `json:"timezone"`
+	IsActive  bool            `json:"is_active"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Metadata  json.RawMessage `json:"metadata"`
+}
+
+type InboxItem struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         string     `json:"user_id"`
+	NotificationID uuid.UUID  `json:"notification_id"`
+	ReadAt         *time.Time `json:"read_at"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 type Notification struct {
-	ID             uuid.UUID       `json:"id"`
-	IdempotencyKey *string         `json:"idempotency_key"`
-	Type           string          `json:"type"`
-	Title          *string         `json:"title"`
-	Body           *string         `json:"body"`
-	Icon           *string         `json:"icon"`
-	Url            *string         `json:"url"`
-	Locale         *string         `json:"locale"`
-	Data           json.RawMessage `json:"data"`
-	Status         string          `json:"status"`
-	DedupeKey      *string         `json:"dedupe_key"`
-	TtlSeconds     *int32          `json:"ttl_seconds"`
-	Priority       *string         `json:"priority"`
-	CreatedAt      time.Time       `json:"created_at"`
+	ID                   uuid.UUID       `json:"id"`
+	IdempotencyKey       *string         `json:"idempotency_key"`
+	Type                 string          `json:"type"`
+	Title                *string         `json:"title"`
+	Body                 *string         `json:"body"`
+	Icon                 *string         `json:"icon"`
+	Url                  *string         `json:"url"`
+	Locale               *string         `json:"locale"`
+	Data                 json.RawMessage `json:"data"`
+	Status               string          `json:"status"`
+	DedupeKey            *string         `json:"dedupe_key"`
+	TtlSeconds           *int32          `json:"ttl_seconds"`
+	Priority             *string         `json:"priority"`
+	CreatedAt            time.Time       `json:"created_at"`
+	TemplateID           pgtype.UUID     `json:"template_id"`
+	TemplateVersion      *int32          `json:"template_version"`
+	ExpectedDeliveries   *int32          `json:"expected_deliveries"`
+	CompletedAt          *time.Time      `json:"completed_at"`
+	RequestHash          *string         `json:"request_hash"`
+	IdempotencyExpiresAt *time.Time      `json:"idempotency_expires_at"`
+	SendAt               *time.Time      `json:"send_at"`
+	DeliverLocalTime     *string         `json:"deliver_local_time"`
+	Topics               []string        `json:"topics"`
+	Segment              json.RawMessage `json:"segment"`
+	Channels             []string        `json:"channels"`
+	CallbackUrl          *string         `json:"callback_url"`
+	CallbackAttempts     bool            `json:"callback_attempts"`
+	CreatedBy            *string         `json:"created_by"`
 }
 
 type NotificationAttempt struct {
-	ID             uuid.UUID   `json:"id"`
-	NotificationID uuid.UUID   `json:"notification_id"`
-	SubscriptionID pgtype.UUID `json:"subscription_id"`
-	UserID         string      `json:"user_id"`
-	Status         string      `json:"status"`
-	HttpStatus     *int32      `json:"http_status"`
-	LatencyMs      *int32      `json:"latency_ms"`
-	Error          *string     `json:"error"`
-	RetryCount     *int32      `json:"retry_count"`
-	Pruned         bool        `json:"pruned"`
-	CreatedAt      time.Time   `json:"created_at"`
+	ID              uuid.UUID   `json:"id"`
+	NotificationID  uuid.UUID   `json:"notification_id"`
+	SubscriptionID  pgtype.UUID `json:"subscription_id"`
+	UserID          string      `json:"user_id"`
+	Status          string      `json:"status"`
+	HttpStatus      *int32      `json:"http_status"`
+	LatencyMs       *int32      `json:"latency_ms"`
+	Error           *string     `json:"error"`
+	RetryCount      *int32      `json:"retry_count"`
+	Pruned          bool        `json:"pruned"`
+	CreatedAt       time.Time   `json:"created_at"`
+	Reason          *string     `json:"reason"`
+	Channel         string      `json:"channel"`
+	ContactPointID  pgtype.UUID `json:"contact_point_id"`
+	FallbackID      pgtype.UUID `json:"fallback_id"`
+	TopicWebhookID  pgtype.UUID `json:"topic_webhook_id"`
+	TemplateID      pgtype.UUID `json:"template_id"`
+	TemplateVersion *int32      `json:"template_version"`
+}
+
+type NotificationDedupeClaim struct {
+	UserID         string    `json:"user_id"`
+	DedupeKey      string    `json:"dedupe_key"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	WindowSeconds  int32     `json:"window_seconds"`
+}
+
+type NotificationEvent struct {
+	ID             uuid.UUID `json:"id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	UserID         string    `json:"user_id"`
+	Event          string    `json:"event"`
+	Source         string    `json:"source"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type NotificationFallback struct {
+	ID             uuid.UUID  `json:"id"`
+	NotificationID uuid.UUID  `json:"notification_id"`
+	UserID         string     `json:"user_id"`
+	Channel        string     `json:"channel"`
+	DelaySeconds   int32      `json:"delay_seconds"`
+	ExpectedPush   int32      `json:"expected_push"`
+	Status         string     `json:"status"`
+	Deliveries     int32      `json:"deliveries"`
+	ProcessAt      *time.Time `json:"process_at"`
+	TriggeredAt    *time.Time `json:"triggered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type NotificationOutbox struct {
+	ID             uuid.UUID       `json:"id"`
+	NotificationID uuid.UUID       `json:"notification_id"`
+	TaskType       string          `json:"task_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Queue          string          `json:"queue"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DispatchedAt   *time.Time      `json:"dispatched_at"`
+	ProcessAt      *time.Time      `json:"process_at"`
 }
 
 type NotificationRecipient struct {
 	NotificationID uuid.UUID `json:"notification_id"`
 	UserID         string    `json:"user_id"`
 }
+
+type NotificationRoutingPolicy struct {
+	Type                 string    `json:"type"`
+	FallbackChannel      string    `json:"fallback_channel"`
+	FallbackDelaySeconds int32     `json:"fallback_delay_seconds"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type NotificationTemplate struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	Locale    string    `json:"locale"`
+	Version   int32     `json:"version"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Url       *string   `json:"url"`
+	Icon      *string   `json:"icon"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NotificationTopic struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TopicSubscription struct {
+	Topic     string    `json:"topic"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TopicWebhook struct {
+	ID        uuid.UUID `json:"id"`
+	Topic     string    `json:"topic"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserContactPoint struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    string    `json:"user_id"`
+	Channel   string    `json:"channel"`
+	Address   string    `json:"address"`
+	Locale    *string   `json:"locale"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Secret    *string   `json:"secret"`
+}
+
+type UserNotificationPreference struct {
+	UserID      string    `json:"user_id"`
+	TypePattern string    `json:"type_pattern"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type UserQuietHour struct {
+	UserID    string    `json:"user_id"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	Timezone  *string   `json:"timezone"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -11,8 +11,23 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelScheduledNotification = `-- name: CancelScheduledNotification :execrows
+UPDATE notifications
+SET status = 'cancelled', completed_at = now()
+WHERE id = $1 AND status = 'scheduled'
+`
+
+func (q *Queries) CancelScheduledNotification(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelScheduledNotification, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const countNotificationsByStatus = `-- name: CountNotificationsByStatus :one
 SELECT COUNT(*) FROM notifications
 WHERE status = $1
@@ -38,26 +53,50 @@ INSERT INTO notifications (
   status,
   dedupe_key,
   ttl_seconds,
-  priority
+  priority,
+  template_id,
+  template_version,
+  request_hash,
+  idempotency_expires_at,
+  send_at,
+  deliver_local_time,
+  topics,
+  segment,
+  channels,
+  callback_url,
+  callback_attempts,
+  created_by
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
 )
-RETURNING id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at
+RETURNING id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by
 `
 
 type CreateNotificationParams struct {
-	IdempotencyKey *string         `json:"idempotency_key"`
-	Type           string          `json:"type"`
-	Title          *string         `json:"title"`
-	Body           *string         `json:"body"`
-	Icon           *string         `json:"icon"`
-	Url            *string         `json:"url"`
-	Locale         *string         `json:"locale"`
-	Data           json.RawMessage `json:"data"`
-	Status         string          `json:"status"`
-	DedupeKey      *string         `json:"dedupe_key"`
-	TtlSeconds     *int32          `json:"ttl_seconds"`
-	Priority       *string         `json:"priority"`
+	IdempotencyKey       *string         `json:"idempotency_key"`
+	Type                 string          `json:"type"`
+	Title                *string         `json:"title"`
+	Body                 *string         `json:"body"`
+	Icon                 *string         `json:"icon"`
+	Url                  *string         `json:"url"`
+	Locale               *string         `json:"locale"`
+	Data                 json.RawMessage `json:"data"`
+	Status               string          `json:"status"`
+	DedupeKey            *string         `json:"dedupe_key"`
+	TtlSeconds           *int32          `json:"ttl_seconds"`
+	Priority             *string         `json:"priority"`
+	TemplateID           pgtype.UUID     `json:"template_id"`
+	TemplateVersion      *int32          `json:"template_version"`
+	RequestHash          *string         `json:"request_hash"`
+	IdempotencyExpiresAt *time.Time      `json:"idempotency_expires_at"`
+	SendAt               *time.Time      `json:"send_at"`
+	DeliverLocalTime     *string         `json:"deliver_local_time"`
+	Topics               []string        `json:"topics"`
+	Segment              json.RawMessage `json:"segment"`
+	Channels             []string        `json:"channels"`
+	CallbackUrl          *string         `json:"callback_url"`
+	CallbackAttempts     bool            `json:"callback_attempts"`
+	CreatedBy            *string         `json:"created_by"`
 }
 
 func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
@@ -74,6 +113,18 @@ func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotification
 		arg.DedupeKey,
 		arg.TtlSeconds,
 		arg.Priority,
+		arg.TemplateID,
+		arg.TemplateVersion,
+		arg.RequestHash,
+		arg.IdempotencyExpiresAt,
+		arg.SendAt,
+		arg.DeliverLocalTime,
+		arg.Topics,
+		arg.Segment,
+		arg.Channels,
+		arg.CallbackUrl,
+		arg.CallbackAttempts,
+		arg.CreatedBy,
 	)
 	var i Notification
 	err := row.Scan(
@@ -91,6 +142,20 @@ func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotification
 		&i.TtlSeconds,
 		&i.Priority,
 		&i.CreatedAt,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.ExpectedDeliveries,
+		&i.CompletedAt,
+		&i.RequestHash,
+		&i.IdempotencyExpiresAt,
+		&i.SendAt,
+		&i.DeliverLocalTime,
+		&i.Topics,
+		&i.Segment,
+		&i.Channels,
+		&i.CallbackUrl,
+		&i.CallbackAttempts,
+		&i.CreatedBy,
 	)
 	return i, err
 }
@@ -116,7 +181,7 @@ func (q *Queries) DeleteOldNotifications(ctx context.Context, createdAt time.Tim
 }
 
 const findNotificationsByDedupeKey = `-- name: FindNotificationsByDedupeKey :many
-SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at FROM notifications
+SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by FROM notifications
 WHERE dedupe_key = $1
   AND created_at > $2
 ORDER BY created_at DESC
@@ -151,6 +216,20 @@ func (q *Queries) FindNotificationsByDedupeKey(ctx context.Context, arg FindNoti
 			&i.TtlSeconds,
 			&i.Priority,
 			&i.CreatedAt,
+			&i.TemplateID,
+			&i.TemplateVersion,
+			&i.ExpectedDeliveries,
+			&i.CompletedAt,
+			&i.RequestHash,
+			&i.IdempotencyExpiresAt,
+			&i.SendAt,
+			&i.DeliverLocalTime,
+			&i.Topics,
+			&i.Segment,
+			&i.Channels,
+			&i.CallbackUrl,
+			&i.CallbackAttempts,
+			&i.CreatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -163,7 +242,7 @@ func (q *Queries) FindNotificationsByDedupeKey(ctx context.Context, arg FindNoti
 }
 
 const getNotification = `-- name: GetNotification :one
-SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at FROM notifications
+SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by FROM notifications
 WHERE id = $1 LIMIT 1
 `
 
@@ -185,17 +264,37 @@ func (q *Queries) GetNotification(ctx context.Context, id uuid.UUID) (Notificati
 		&i.TtlSeconds,
 		&i.Priority,
 		&i.CreatedAt,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.ExpectedDeliveries,
+		&i.CompletedAt,
+		&i.RequestHash,
+		&i.IdempotencyExpiresAt,
+		&i.SendAt,
+		&i.DeliverLocalTime,
+		&i.Topics,
+		&i.Segment,
+		&i.Channels,
+		&i.CallbackUrl,
+		&i.CallbackAttempts,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
 const getNotificationByIdempotencyKey = `-- name: GetNotificationByIdempotencyKey :one
-SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at FROM notifications
-WHERE idempotency_key = $1 LIMIT 1
+SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by FROM notifications
+WHERE COALESCE(created_by, 'shared') = $1::text AND idempotency_key = $2::text
+LIMIT 1
 `
 
-func (q *Queries) GetNotificationByIdempotencyKey(ctx context.Context, idempotencyKey *string) (Notification, error) {
-	row := q.db.QueryRow(ctx, getNotificationByIdempotencyKey, idempotencyKey)
+type GetNotificationByIdempotencyKeyParams struct {
+	CreatedBy      string `json:"created_by"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) GetNotificationByIdempotencyKey(ctx context.Context, arg GetNotificationByIdempotencyKeyParams) (Notification, error) {
+	row := q.db.QueryRow(ctx, getNotificationByIdempotencyKey, arg.CreatedBy, arg.IdempotencyKey)
 	var i Notification
 	err := row.Scan(
 		&i.ID,
@@ -212,12 +311,50 @@ func (q *Queries) GetNotificationByIdempotencyKey(ctx context.Context, idempoten
 		&i.TtlSeconds,
 		&i.Priority,
 		&i.CreatedAt,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.ExpectedDeliveries,
+		&i.CompletedAt,
+		&i.RequestHash,
+		&i.IdempotencyExpiresAt,
+		&i.SendAt,
+		&i.DeliverLocalTime,
+		&i.Topics,
+		&i.Segment,
+		&i.Channels,
+		&i.CallbackUrl,
+		&i.CallbackAttempts,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const getNotificationCallback = `-- name: GetNotificationCallback :one
+SELECT type, callback_url, callback_attempts, created_by FROM notifications
+WHERE id = $1
+`
+
+type GetNotificationCallbackRow struct {
+	Type             string  `json:"type"`
+	CallbackUrl      *string `json:"callback_url"`
+	CallbackAttempts bool    `json:"callback_attempts"`
+	CreatedBy        *string `json:"created_by"`
+}
+
+func (q *Queries) GetNotificationCallback(ctx context.Context, id uuid.UUID) (GetNotificationCallbackRow, error) {
+	row := q.db.QueryRow(ctx, getNotificationCallback, id)
+	var i GetNotificationCallbackRow
+	err := row.Scan(
+		&i.Type,
+		&i.CallbackUrl,
+		&i.CallbackAttempts,
+		&i.CreatedBy,
 	)
 	return i, err
 }
 
 const listNotifications = `-- name: ListNotifications :many
-SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at FROM notifications
+SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by FROM notifications
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -251,6 +388,20 @@ func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsPa
 			&i.TtlSeconds,
 			&i.Priority,
 			&i.CreatedAt,
+			&i.TemplateID,
+			&i.TemplateVersion,
+			&i.ExpectedDeliveries,
+			&i.CompletedAt,
+			&i.RequestHash,
+			&i.IdempotencyExpiresAt,
+			&i.SendAt,
+			&i.DeliverLocalTime,
+			&i.Topics,
+			&i.Segment,
+			&i.Channels,
+			&i.CallbackUrl,
+			&i.CallbackAttempts,
+			&i.CreatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -263,7 +414,7 @@ func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsPa
 }
 
 const listNotificationsByStatus = `-- name: ListNotificationsByStatus :many
-SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at FROM notifications
+SELECT id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by FROM notifications
 WHERE status = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -299,6 +450,20 @@ func (q *Queries) ListNotificationsByStatus(ctx context.Context, arg ListNotific
 			&i.TtlSeconds,
 			&i.Priority,
 			&i.CreatedAt,
+			&i.TemplateID,
+			&i.TemplateVersion,
+			&i.ExpectedDeliveries,
+			&i.CompletedAt,
+			&i.RequestHash,
+			&i.IdempotencyExpiresAt,
+			&i.SendAt,
+			&i.DeliverLocalTime,
+			&i.Topics,
+			&i.Segment,
+			&i.Channels,
+			&i.CallbackUrl,
+			&i.CallbackAttempts,
+			&i.CreatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -310,11 +475,132 @@ func (q *Queries) ListNotificationsByStatus(ctx context.Context, arg ListNotific
 	return items, nil
 }
 
+const releaseExpiredIdempotencyKey = `-- name: ReleaseExpiredIdempotencyKey :execrows
+UPDATE notifications
+SET idempotency_key = NULL
+WHERE COALESCE(created_by, 'shared') = $1::text AND idempotency_key = $2::text
+  AND idempotency_expires_at <= now()
+`
+
+type ReleaseExpiredIdempotencyKeyParams struct {
+	CreatedBy      string `json:"created_by"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) ReleaseExpiredIdempotencyKey(ctx context.Context, arg ReleaseExpiredIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseExpiredIdempotencyKey, arg.CreatedBy, arg.IdempotencyKey)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const releaseScheduledNotification = `-- name: ReleaseScheduledNotification :execrows
+UPDATE notifications
+SET status = 'queued'
+WHERE id = $1 AND status = 'scheduled'
+`
+
+func (q *Queries) ReleaseScheduledNotification(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseScheduledNotification, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reopenNotification = `-- name: ReopenNotification :exec
+UPDATE notifications
+SET status = 'sending', completed_at = NULL
+WHERE id = $1 AND expected_deliveries IS NOT NULL
+`
+
+func (q *Queries) ReopenNotification(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, reopenNotification, id)
+	return err
+}
+
+const rollupNotificationStatus = `-- name: RollupNotificationStatus :execrows
+WITH latest AS (
+  SELECT DISTINCT ON (COALESCE(a.subscription_id, a.contact_point_id, a.topic_webhook_id)) a.status
+  FROM notification_attempts a
+  WHERE a.notification_id = $1
+  ORDER BY COALESCE(a.subscription_id, a.contact_point_id, a.topic_webhook_id), a.created_at DESC
+), counts AS (
+  SELECT
+    COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
+    COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+    COUNT(*) FILTER (WHERE status = 'pruned') AS pruned,
+    COUNT(*) FILTER (WHERE status = 'skipped') AS skipped
+  FROM latest
+)
+UPDATE notifications n
+SET
+  status = CASE
+    WHEN c.delivered > 0 AND c.failed + c.pruned = 0 THEN 'sent'
+    WHEN c.delivered > 0 THEN 'partial'
+    WHEN c.failed + c.pruned = 0 AND c.skipped > 0 THEN 'suppressed'
+    ELSE 'failed'
+  END,
+  completed_at = now()
+FROM counts c
+WHERE n.id = $1
+  AND n.completed_at IS NULL
+  AND n.expected_deliveries IS NOT NULL
+  AND c.delivered + c.failed + c.pruned + c.skipped >= n.expected_deliveries + (
+    SELECT COALESCE(SUM(f.deliveries), 0) FROM notification_fallbacks f WHERE f.notification_id = n.id
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM notification_fallbacks f
+    WHERE f.notification_id = n.id
+      AND f.status = 'pending'
+      AND NOT EXISTS (
+        SELECT 1 FROM notification_attempts a
+        WHERE a.notification_id = f.notification_id AND a.user_id = f.user_id
+          AND a.channel = 'push' AND a.status = 'delivered'
+      )
+      AND (
+        SELECT COUNT(*) FROM (
+          SELECT DISTINCT ON (a.subscription_id) a.status
+          FROM notification_attempts a
+          WHERE a.notification_id = f.notification_id AND a.user_id = f.user_id
+            AND a.channel = 'push'
+          ORDER BY a.subscription_id, a.created_at DESC
+        ) latest
+        WHERE latest.status IN ('failed', 'pruned', 'skipped')
+      ) >= f.expected_push
+  )
+`
+
+func (q *Queries) RollupNotificationStatus(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, rollupNotificationStatus, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const startNotificationDelivery = `-- name: StartNotificationDelivery :exec
+UPDATE notifications
+SET expected_deliveries = $2, status = 'sending'
+WHERE id = $1 AND completed_at IS NULL
+`
+
+type StartNotificationDeliveryParams struct {
+	ID                 uuid.UUID `json:"id"`
+	ExpectedDeliveries *int32    `json:"expected_deliveries"`
+}
+
+func (q *Queries) StartNotificationDelivery(ctx context.Context, arg StartNotificationDeliveryParams) error {
+	_, err := q.db.Exec(ctx, startNotificationDelivery, arg.ID, arg.ExpectedDeliveries)
+	return err
+}
+
 const updateNotificationStatus = `-- name: UpdateNotificationStatus :one
 UPDATE notifications
 SET status = $2
 WHERE id = $1
-RETURNING id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at
+RETURNING id, idempotency_key, type, title, body, icon, url, locale, data, status, dedupe_key, ttl_seconds, priority, created_at, template_id, template_version, expected_deliveries, completed_at, request_hash, idempotency_expires_at, send_at, deliver_local_time, topics, segment, channels, callback_url, callback_attempts, created_by
 `
 
 type UpdateNotificationStatusParams struct {
@@ -340,6 +626,20 @@ func (q *Queries) UpdateNotificationStatus(ctx context.Context, arg UpdateNotifi
 		&i.TtlSeconds,
 		&i.Priority,
 		&i.CreatedAt,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.ExpectedDeliveries,
+		&i.CompletedAt,
+		&i.RequestHash,
+		&i.IdempotencyExpiresAt,
+		&i.SendAt,
+		&i.DeliverLocalTime,
+		&i.Topics,
+		&i.Segment,
+		&i.Channels,
+		&i.CallbackUrl,
+		&i.CallbackAttempts,
+		&i.CreatedBy,
 	)
 	return i, err
 }
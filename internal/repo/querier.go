@@ -13,48 +13,129 @@ import (
 )
 
 type Querier interface {
+	ArchiveInboxItem(ctx context.Context, arg ArchiveInboxItemParams) (int64, error)
+	CancelScheduledNotification(ctx context.Context, id uuid.UUID) (int64, error)
 	CheckRecipientExists(ctx context.Context, arg CheckRecipientExistsParams) (bool, error)
+	ClaimDedupeKey(ctx context.Context, arg ClaimDedupeKeyParams) (int64, error)
+	ClaimPendingOutboxMessages(ctx context.Context, limit int32) ([]NotificationOutbox, error)
 	CountActiveSubscriptionsByUser(ctx context.Context, userID string) (int64, error)
 	CountDeliveryAttemptsByNotification(ctx context.Context, notificationID uuid.UUID) (int64, error)
 	CountDeliveryAttemptsByStatus(ctx context.Context, status string) (int64, error)
+	CountExistingTopics(ctx context.Context, names []string) (int64, error)
 	CountNotificationsByStatus(ctx context.Context, status string) (int64, error)
 	CountRecipientsByNotification(ctx context.Context, notificationID uuid.UUID) (int64, error)
+	CountUnreadInboxItems(ctx context.Context, userID string) (int64, error)
+	CreateAPIClient(ctx context.Context, arg CreateAPIClientParams) (ApiClient, error)
+	CreateAPIClientSecret(ctx context.Context, arg CreateAPIClientSecretParams) (ApiClientSecret, error)
+	CreateContactPoint(ctx context.Context, arg CreateContactPointParams) (UserContactPoint, error)
 	CreateDeliveryAttempt(ctx context.Context, arg CreateDeliveryAttemptParams) (NotificationAttempt, error)
 	CreateDeviceSubscription(ctx context.Context, arg CreateDeviceSubscriptionParams) (DeviceSubscription, error)
+	CreateFallback(ctx context.Context, arg CreateFallbackParams) (NotificationFallback, error)
+	CreateInboxItem(ctx context.Context, arg CreateInboxItemParams) (InboxItem, error)
 	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateNotificationEvent(ctx context.Context, arg CreateNotificationEventParams) (int64, error)
+	CreateOutboxMessage(ctx context.Context, arg CreateOutboxMessageParams) (NotificationOutbox, error)
+	CreatePreference(ctx context.Context, arg CreatePreferenceParams) (UserNotificationPreference, error)
 	CreateRecipient(ctx context.Context, arg CreateRecipientParams) error
 	CreateRecipientsBatch(ctx context.Context, arg []CreateRecipientsBatchParams) (int64, error)
+	CreateTemplate(ctx context.Context, arg CreateTemplateParams) (NotificationTemplate, error)
+	CreateTopic(ctx context.Context, arg CreateTopicParams) (NotificationTopic, error)
+	CreateTopicWebhook(ctx context.Context, arg CreateTopicWebhookParams) (TopicWebhook, error)
 	DeactivateDeviceSubscription(ctx context.Context, id uuid.UUID) error
+	DeactivateTemplates(ctx context.Context, arg DeactivateTemplatesParams) error
+	DeleteAPIClientSecret(ctx context.Context, arg DeleteAPIClientSecretParams) (int64, error)
+	DeleteContactPoint(ctx context.Context, arg DeleteContactPointParams) (int64, error)
 	DeleteDeviceSubscription(ctx context.Context, id uuid.UUID) error
 	DeleteDeviceSubscriptionByEndpoint(ctx context.Context, endpoint string) error
+	DeleteDispatchedOutboxMessages(ctx context.Context, before time.Time) (int64, error)
+	DeleteInboxItem(ctx context.Context, arg DeleteInboxItemParams) (int64, error)
 	DeleteNotification(ctx context.Context, id uuid.UUID) error
 	DeleteOldAttempts(ctx context.Context, createdAt time.Time) error
 	DeleteOldNotifications(ctx context.Context, createdAt time.Time) error
+	DeletePendingOutboxMessages(ctx context.Context, notificationID uuid.UUID) (int64, error)
+	DeletePreferencesByUser(ctx context.Context, userID string) error
+	DeleteQuietHours(ctx context.Context, userID string) (int64, error)
 	DeleteRecipient(ctx context.Context, arg DeleteRecipientParams) error
 	DeleteRecipientsByNotification(ctx context.Context, notificationID uuid.UUID) error
+	DeleteRoutingPolicy(ctx context.Context, type_ string) (int64, error)
+	DeleteTemplate(ctx context.Context, id uuid.UUID) error
+	DeleteTopic(ctx context.Context, name string) (int64, error)
+	DeleteTopicWebhook(ctx context.Context, arg DeleteTopicWebhookParams) (int64, error)
+	ExtendDedupeClaim(ctx context.Context, arg ExtendDedupeClaimParams) error
 	FindFailedAttemptsBySubscription(ctx context.Context, arg FindFailedAttemptsBySubscriptionParams) ([]NotificationAttempt, error)
 	FindNotificationsByDedupeKey(ctx context.Context, arg FindNotificationsByDedupeKeyParams) ([]Notification, error)
 	FindStaleSubscriptions(ctx context.Context, arg FindStaleSubscriptionsParams) ([]DeviceSubscription, error)
+	GetAPIClientByKeyID(ctx context.Context, keyID string) (ApiClient, error)
+	GetAPIClientCallbackSecret(ctx context.Context, keyID string) (GetAPIClientCallbackSecretRow, error)
+	GetActiveTemplate(ctx context.Context, arg GetActiveTemplateParams) (NotificationTemplate, error)
+	GetContactPoint(ctx context.Context, id uuid.UUID) (UserContactPoint, error)
 	GetDeliveryAttempt(ctx context.Context, id uuid.UUID) (NotificationAttempt, error)
 	GetDeliveryStats(ctx context.Context, createdAt time.Time) (GetDeliveryStatsRow, error)
 	GetDeviceSubscription(ctx context.Context, id uuid.UUID) (DeviceSubscription, error)
 	GetDeviceSubscriptionByEndpoint(ctx context.Context, endpoint string) (DeviceSubscription, error)
+	GetInboxItem(ctx context.Context, arg GetInboxItemParams) (GetInboxItemRow, error)
 	GetNotification(ctx context.Context, id uuid.UUID) (Notification, error)
-	GetNotificationByIdempotencyKey(ctx context.Context, idempotencyKey *string) (Notification, error)
+	GetNotificationByIdempotencyKey(ctx context.Context, arg GetNotificationByIdempotencyKeyParams) (Notification, error)
+	GetNotificationCallback(ctx context.Context, id uuid.UUID) (GetNotificationCallbackRow, error)
+	GetNotificationDeliveryCounts(ctx context.Context, notificationID uuid.UUID) (GetNotificationDeliveryCountsRow, error)
+	GetNotificationEventCounts(ctx context.Context, notificationID uuid.UUID) (GetNotificationEventCountsRow, error)
+	GetPendingFallback(ctx context.Context, arg GetPendingFallbackParams) (NotificationFallback, error)
+	GetQuietHours(ctx context.Context, userID string) (UserQuietHour, error)
 	GetRecipientsByNotification(ctx context.Context, notificationID uuid.UUID) ([]NotificationRecipient, error)
 	GetRecipientsByUser(ctx context.Context, userID string) ([]NotificationRecipient, error)
+	GetRoutingPolicy(ctx context.Context, type_ string) (NotificationRoutingPolicy, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (NotificationTemplate, error)
+	GetTopic(ctx context.Context, name string) (NotificationTopic, error)
+	GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (GetWebhookEndpointRow, error)
+	IsNotificationRecipient(ctx context.Context, arg IsNotificationRecipientParams) (bool, error)
+	ListAPIClientSecrets(ctx context.Context, clientID uuid.UUID) ([]ApiClientSecret, error)
+	ListAPIClients(ctx context.Context) ([]ApiClient, error)
+	ListActiveContactPointsByUser(ctx context.Context, arg ListActiveContactPointsByUserParams) ([]UserContactPoint, error)
 	ListActiveDeviceSubscriptionsByUser(ctx context.Context, userID string) ([]DeviceSubscription, error)
+	ListActiveTopicWebhooks(ctx context.Context, topics []string) ([]TopicWebhook, error)
+	ListContactPointsByUser(ctx context.Context, userID string) ([]UserContactPoint, error)
 	ListDeliveryAttemptsByNotification(ctx context.Context, notificationID uuid.UUID) ([]NotificationAttempt, error)
 	ListDeliveryAttemptsByStatus(ctx context.Context, arg ListDeliveryAttemptsByStatusParams) ([]NotificationAttempt, error)
 	ListDeliveryAttemptsBySubscription(ctx context.Context, arg ListDeliveryAttemptsBySubscriptionParams) ([]NotificationAttempt, error)
 	ListDeliveryAttemptsByUser(ctx context.Context, arg ListDeliveryAttemptsByUserParams) ([]NotificationAttempt, error)
 	ListDeviceSubscriptionsByUser(ctx context.Context, userID string) ([]DeviceSubscription, error)
+	ListEngagementByType(ctx context.Context, since time.Time) ([]ListEngagementByTypeRow, error)
+	ListFallbacksByNotification(ctx context.Context, notificationID uuid.UUID) ([]NotificationFallback, error)
+	ListInboxItemsAfter(ctx context.Context, arg ListInboxItemsAfterParams) ([]ListInboxItemsAfterRow, error)
+	ListInboxItemsPage(ctx context.Context, arg ListInboxItemsPageParams) ([]ListInboxItemsPageRow, error)
 	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
 	ListNotificationsByStatus(ctx context.Context, arg ListNotificationsByStatusParams) ([]Notification, error)
+	ListOutboxMessagesByNotification(ctx context.Context, notificationID uuid.UUID) ([]NotificationOutbox, error)
+	ListPreferencesByUser(ctx context.Context, userID string) ([]UserNotificationPreference, error)
+	ListRoutingPolicies(ctx context.Context) ([]NotificationRoutingPolicy, error)
+	ListSegmentUsersPage(ctx context.Context, arg ListSegmentUsersPageParams) ([]string, error)
+	ListTemplates(ctx context.Context, arg ListTemplatesParams) ([]NotificationTemplate, error)
+	ListTopicMembersPage(ctx context.Context, arg ListTopicMembersPageParams) ([]string, error)
+	ListTopicSubscribers(ctx context.Context, arg ListTopicSubscribersParams) ([]TopicSubscription, error)
+	ListTopicWebhooks(ctx context.Context, topic string) ([]TopicWebhook, error)
+	ListTopics(ctx context.Context, arg ListTopicsParams) ([]NotificationTopic, error)
+	MarkAllInboxItemsRead(ctx context.Context, userID string) (int64, error)
+	MarkInboxItemRead(ctx context.Context, arg MarkInboxItemReadParams) (int64, error)
+	MarkOutboxMessageDispatched(ctx context.Context, id uuid.UUID) error
 	MarkSubscriptionAsPruned(ctx context.Context, subscriptionID pgtype.UUID) error
+	RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error
+	ReleaseDedupeClaim(ctx context.Context, arg ReleaseDedupeClaimParams) error
+	ReleaseExpiredIdempotencyKey(ctx context.Context, arg ReleaseExpiredIdempotencyKeyParams) (int64, error)
+	ReleaseScheduledNotification(ctx context.Context, id uuid.UUID) (int64, error)
+	ReleaseUndeliveredDedupeClaims(ctx context.Context, notificationID uuid.UUID) (int64, error)
+	ReopenNotification(ctx context.Context, id uuid.UUID) error
+	RollupNotificationStatus(ctx context.Context, id uuid.UUID) (int64, error)
+	SetTemplateActive(ctx context.Context, arg SetTemplateActiveParams) (NotificationTemplate, error)
+	StartNotificationDelivery(ctx context.Context, arg StartNotificationDeliveryParams) error
+	SubscribeToTopic(ctx context.Context, arg SubscribeToTopicParams) error
+	TriggerFallback(ctx context.Context, arg TriggerFallbackParams) (NotificationFallback, error)
+	UnsubscribeFromTopic(ctx context.Context, arg UnsubscribeFromTopicParams) (int64, error)
+	UpdateAPIClient(ctx context.Context, arg UpdateAPIClientParams) (ApiClient, error)
 	UpdateDeliveryAttemptStatus(ctx context.Context, arg UpdateDeliveryAttemptStatusParams) (NotificationAttempt, error)
 	UpdateDeviceSubscription(ctx context.Context, arg UpdateDeviceSubscriptionParams) (DeviceSubscription, error)
 	UpdateNotificationStatus(ctx context.Context, arg UpdateNotificationStatusParams) (Notification, error)
+	UpsertQuietHours(ctx context.Context, arg UpsertQuietHoursParams) (UserQuietHour, error)
+	UpsertRoutingPolicy(ctx context.Context, arg UpsertRoutingPolicyParams) (NotificationRoutingPolicy, error)
 }
 
 var _ Querier = (*Queries)(nil)
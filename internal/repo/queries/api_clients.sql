@@ -0,0 +1,54 @@
+-- name: CreateAPIClient :one
+INSERT INTO api_clients (
+  key_id,
+  name,
+  scopes,
+  callback_url
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetAPIClientByKeyID :one
+SELECT * FROM api_clients
+WHERE key_id = $1 LIMIT 1;
+
+-- name: GetAPIClientCallbackSecret :one
+SELECT c.status, s.secret_ciphertext FROM api_clients c
+JOIN api_client_secrets s ON s.client_id = c.id
+WHERE c.key_id = $1
+ORDER BY s.created_at DESC
+LIMIT 1;
+
+-- name: ListAPIClients :many
+SELECT * FROM api_clients
+ORDER BY created_at, key_id;
+
+-- name: UpdateAPIClient :one
+UPDATE api_clients
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  scopes = COALESCE(sqlc.narg('scopes'), scopes),
+  status = COALESCE(sqlc.narg('status'), status),
+  callback_url = CASE WHEN sqlc.narg('callback_url')::text IS NULL THEN callback_url ELSE NULLIF(sqlc.narg('callback_url')::text, '') END,
+  updated_at = now()
+WHERE key_id = sqlc.arg('key_id')
+RETURNING *;
+
+-- name: CreateAPIClientSecret :one
+INSERT INTO api_client_secrets (
+  client_id,
+  secret_ciphertext
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: ListAPIClientSecrets :many
+SELECT * FROM api_client_secrets
+WHERE client_id = $1
+ORDER BY created_at;
+
+-- name: DeleteAPIClientSecret :execrows
+DELETE FROM api_client_secrets
+WHERE id = $1 AND client_id = $2;
@@ -0,0 +1,31 @@
+-- name: CreateContactPoint :one
+INSERT INTO user_contact_points (
+  user_id,
+  channel,
+  address,
+  locale,
+  secret
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, channel, address) DO UPDATE
+SET locale = EXCLUDED.locale, is_active = true, updated_at = now()
+RETURNING *;
+
+-- name: GetContactPoint :one
+SELECT * FROM user_contact_points
+WHERE id = $1 LIMIT 1;
+
+-- name: ListContactPointsByUser :many
+SELECT * FROM user_contact_points
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: ListActiveContactPointsByUser :many
+SELECT * FROM user_contact_points
+WHERE user_id = $1 AND channel = $2 AND is_active = true
+ORDER BY created_at;
+
+-- name: DeleteContactPoint :execrows
+DELETE FROM user_contact_points
+WHERE id = $1 AND user_id = $2;
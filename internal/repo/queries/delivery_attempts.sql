@@ -7,9 +7,16 @@ INSERT INTO notification_attempts (
   http_status,
   latency_ms,
   error,
-  retry_count
+  retry_count,
+  reason,
+  channel,
+  contact_point_id,
+  fallback_id,
+  topic_webhook_id,
+  template_id,
+  template_version
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 )
 RETURNING *;
 
@@ -83,3 +90,55 @@ ORDER BY created_at DESC;
 -- name: DeleteOldAttempts :exec
 DELETE FROM notification_attempts
 WHERE created_at < $1;
+
+-- name: GetNotificationDeliveryCounts :one
+WITH latest AS (
+  SELECT DISTINCT ON (COALESCE(subscription_id, contact_point_id, topic_webhook_id)) status
+  FROM notification_attempts
+  WHERE notification_id = $1
+  ORDER BY COALESCE(subscription_id, contact_point_id, topic_webhook_id), created_at DESC
+)
+SELECT
+  COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
+  COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+  COUNT(*) FILTER (WHERE status = 'pruned') AS pruned,
+  COUNT(*) FILTER (WHERE status = 'skipped') AS skipped,
+  COUNT(*) FILTER (WHERE status = 'retrying') AS retrying,
+  COUNT(*) FILTER (WHERE status = 'deferred') AS deferred
+FROM latest;
+
+-- name: ClaimDedupeKey :execrows
+INSERT INTO notification_dedupe_claims (
+  user_id,
+  dedupe_key,
+  notification_id,
+  expires_at,
+  window_seconds
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, dedupe_key) DO UPDATE
+SET
+  notification_id = EXCLUDED.notification_id,
+  expires_at = EXCLUDED.expires_at,
+  window_seconds = EXCLUDED.window_seconds
+WHERE notification_dedupe_claims.expires_at <= now()
+  OR notification_dedupe_claims.notification_id = EXCLUDED.notification_id;
+
+-- name: ExtendDedupeClaim :exec
+UPDATE notification_dedupe_claims
+SET expires_at = now() + make_interval(secs => window_seconds)
+WHERE user_id = $1 AND notification_id = $2;
+
+-- name: ReleaseDedupeClaim :exec
+DELETE FROM notification_dedupe_claims
+WHERE user_id = $1 AND notification_id = $2;
+
+-- name: ReleaseUndeliveredDedupeClaims :execrows
+DELETE FROM notification_dedupe_claims c
+WHERE c.notification_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM notification_attempts a
+    WHERE a.notification_id = c.notification_id AND a.user_id = c.user_id
+      AND a.status = 'delivered'
+  );
@@ -7,9 +7,10 @@ INSERT INTO device_subscriptions (
   device_id,
   user_agent,
   locale,
-  timezone
+  timezone,
+  metadata
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 RETURNING *;
 
@@ -68,3 +69,12 @@ WHERE is_active = true
   AND updated_at < $1
 ORDER BY updated_at ASC
 LIMIT $2;
+
+-- name: ListSegmentUsersPage :many
+SELECT DISTINCT user_id FROM device_subscriptions
+WHERE is_active = true
+  AND (sqlc.narg('locale')::text IS NULL OR locale = sqlc.narg('locale')::text)
+  AND metadata @> sqlc.arg('metadata')::jsonb
+  AND user_id > sqlc.arg('after_user_id')
+ORDER BY user_id
+LIMIT sqlc.arg('batch_size');
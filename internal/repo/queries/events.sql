@@ -0,0 +1,59 @@
+-- name: CreateNotificationEvent :execrows
+INSERT INTO notification_events (
+  notification_id,
+  user_id,
+  event,
+  source
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (notification_id, user_id, event) DO NOTHING;
+
+-- name: IsNotificationRecipient :one
+SELECT EXISTS(
+  SELECT 1 FROM inbox_items
+  WHERE inbox_items.notification_id = $1 AND inbox_items.user_id = $2
+  UNION ALL
+  SELECT 1 FROM notification_attempts
+  WHERE notification_attempts.notification_id = $1 AND notification_attempts.user_id = $2
+);
+
+-- name: GetNotificationEventCounts :one
+SELECT
+  COUNT(*) FILTER (WHERE event = 'delivered') AS delivered,
+  COUNT(*) FILTER (WHERE event = 'displayed') AS displayed,
+  COUNT(*) FILTER (WHERE event = 'clicked') AS clicked,
+  COUNT(*) FILTER (WHERE event = 'closed') AS closed
+FROM notification_events
+WHERE notification_id = $1;
+
+-- name: ListEngagementByType :many
+WITH delivered AS (
+  SELECT n.type, COUNT(DISTINCT (a.notification_id, a.user_id)) AS delivered
+  FROM notification_attempts a
+  JOIN notifications n ON n.id = a.notification_id
+  WHERE a.status = 'delivered'
+    AND a.user_id <> ''
+    AND n.created_at >= sqlc.arg('since')
+  GROUP BY n.type
+),
+events AS (
+  SELECT
+    n.type,
+    COUNT(*) FILTER (WHERE e.event = 'displayed') AS displayed,
+    COUNT(*) FILTER (WHERE e.event = 'clicked') AS clicked,
+    COUNT(*) FILTER (WHERE e.event = 'closed') AS closed
+  FROM notification_events e
+  JOIN notifications n ON n.id = e.notification_id
+  WHERE n.created_at >= sqlc.arg('since')
+  GROUP BY n.type
+)
+SELECT
+  COALESCE(d.type, e.type)::text AS type,
+  COALESCE(d.delivered, 0)::bigint AS delivered,
+  COALESCE(e.displayed, 0)::bigint AS displayed,
+  COALESCE(e.clicked, 0)::bigint AS clicked,
+  COALESCE(e.closed, 0)::bigint AS closed
+FROM delivered d
+FULL JOIN events e ON e.type = d.type
+ORDER BY type;
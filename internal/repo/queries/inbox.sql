@@ -0,0 +1,63 @@
+-- name: CreateInboxItem :one
+INSERT INTO inbox_items (
+  user_id,
+  notification_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (notification_id, user_id) DO NOTHING
+RETURNING *;
+
+-- name: GetInboxItem :one
+SELECT sqlc.embed(inbox_items), sqlc.embed(notifications)
+FROM inbox_items
+JOIN notifications ON notifications.id = inbox_items.notification_id
+WHERE inbox_items.id = $1 AND inbox_items.user_id = $2
+LIMIT 1;
+
+-- name: ListInboxItemsAfter :many
+SELECT sqlc.embed(inbox_items), sqlc.embed(notifications)
+FROM inbox_items
+JOIN notifications ON notifications.id = inbox_items.notification_id
+WHERE inbox_items.user_id = sqlc.arg('user_id')
+  AND inbox_items.archived_at IS NULL
+  AND (inbox_items.created_at, inbox_items.id) > (sqlc.arg('after_created_at')::timestamptz, sqlc.arg('after_id')::uuid)
+ORDER BY inbox_items.created_at, inbox_items.id
+LIMIT sqlc.arg('batch_size');
+
+-- name: ListInboxItemsPage :many
+SELECT sqlc.embed(inbox_items), sqlc.embed(notifications)
+FROM inbox_items
+JOIN notifications ON notifications.id = inbox_items.notification_id
+WHERE inbox_items.user_id = sqlc.arg('user_id')
+  AND (inbox_items.archived_at IS NOT NULL) = sqlc.arg('archived')::boolean
+  AND (NOT sqlc.arg('unread_only')::boolean OR inbox_items.read_at IS NULL)
+  AND (
+    sqlc.narg('before_created_at')::timestamptz IS NULL
+    OR (inbox_items.created_at, inbox_items.id) < (sqlc.narg('before_created_at')::timestamptz, sqlc.narg('before_id')::uuid)
+  )
+ORDER BY inbox_items.created_at DESC, inbox_items.id DESC
+LIMIT sqlc.arg('batch_size');
+
+-- name: CountUnreadInboxItems :one
+SELECT COUNT(*) FROM inbox_items
+WHERE user_id = $1 AND read_at IS NULL AND archived_at IS NULL;
+
+-- name: MarkInboxItemRead :execrows
+UPDATE inbox_items
+SET read_at = COALESCE(read_at, now())
+WHERE id = $1 AND user_id = $2;
+
+-- name: MarkAllInboxItemsRead :execrows
+UPDATE inbox_items
+SET read_at = now()
+WHERE user_id = $1 AND read_at IS NULL AND archived_at IS NULL;
+
+-- name: ArchiveInboxItem :execrows
+UPDATE inbox_items
+SET archived_at = COALESCE(archived_at, now())
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteInboxItem :execrows
+DELETE FROM inbox_items
+WHERE id = $1 AND user_id = $2;
@@ -11,9 +11,21 @@ INSERT INTO notifications (
   status,
   dedupe_key,
   ttl_seconds,
-  priority
+  priority,
+  template_id,
+  template_version,
+  request_hash,
+  idempotency_expires_at,
+  send_at,
+  deliver_local_time,
+  topics,
+  segment,
+  channels,
+  callback_url,
+  callback_attempts,
+  created_by
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
 )
 RETURNING *;
 
@@ -23,7 +35,8 @@ WHERE id = $1 LIMIT 1;
 
 -- name: GetNotificationByIdempotencyKey :one
 SELECT * FROM notifications
-WHERE idempotency_key = $1 LIMIT 1;
+WHERE COALESCE(created_by, 'shared') = sqlc.arg('created_by')::text AND idempotency_key = sqlc.arg('idempotency_key')::text
+LIMIT 1;
 
 -- name: ListNotifications :many
 SELECT * FROM notifications
@@ -59,3 +72,84 @@ ORDER BY created_at DESC;
 -- name: DeleteOldNotifications :exec
 DELETE FROM notifications
 WHERE created_at < $1;
+
+-- name: StartNotificationDelivery :exec
+UPDATE notifications
+SET expected_deliveries = $2, status = 'sending'
+WHERE id = $1 AND completed_at IS NULL;
+
+-- name: ReleaseScheduledNotification :execrows
+UPDATE notifications
+SET status = 'queued'
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: CancelScheduledNotification :execrows
+UPDATE notifications
+SET status = 'cancelled', completed_at = now()
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: RollupNotificationStatus :execrows
+WITH latest AS (
+  SELECT DISTINCT ON (COALESCE(a.subscription_id, a.contact_point_id, a.topic_webhook_id)) a.status
+  FROM notification_attempts a
+  WHERE a.notification_id = $1
+  ORDER BY COALESCE(a.subscription_id, a.contact_point_id, a.topic_webhook_id), a.created_at DESC
+), counts AS (
+  SELECT
+    COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
+    COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+    COUNT(*) FILTER (WHERE status = 'pruned') AS pruned,
+    COUNT(*) FILTER (WHERE status = 'skipped') AS skipped
+  FROM latest
+)
+UPDATE notifications n
+SET
+  status = CASE
+    WHEN c.delivered > 0 AND c.failed + c.pruned = 0 THEN 'sent'
+    WHEN c.delivered > 0 THEN 'partial'
+    WHEN c.failed + c.pruned = 0 AND c.skipped > 0 THEN 'suppressed'
+    ELSE 'failed'
+  END,
+  completed_at = now()
+FROM counts c
+WHERE n.id = $1
+  AND n.completed_at IS NULL
+  AND n.expected_deliveries IS NOT NULL
+  AND c.delivered + c.failed + c.pruned + c.skipped >= n.expected_deliveries + (
+    SELECT COALESCE(SUM(f.deliveries), 0) FROM notification_fallbacks f WHERE f.notification_id = n.id
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM notification_fallbacks f
+    WHERE f.notification_id = n.id
+      AND f.status = 'pending'
+      AND NOT EXISTS (
+        SELECT 1 FROM notification_attempts a
+        WHERE a.notification_id = f.notification_id AND a.user_id = f.user_id
+          AND a.channel = 'push' AND a.status = 'delivered'
+      )
+      AND (
+        SELECT COUNT(*) FROM (
+          SELECT DISTINCT ON (a.subscription_id) a.status
+          FROM notification_attempts a
+          WHERE a.notification_id = f.notification_id AND a.user_id = f.user_id
+            AND a.channel = 'push'
+          ORDER BY a.subscription_id, a.created_at DESC
+        ) latest
+        WHERE latest.status IN ('failed', 'pruned', 'skipped')
+      ) >= f.expected_push
+  );
+
+-- name: ReopenNotification :exec
+UPDATE notifications
+SET status = 'sending', completed_at = NULL
+WHERE id = $1 AND expected_deliveries IS NOT NULL;
+
+-- name: ReleaseExpiredIdempotencyKey :execrows
+UPDATE notifications
+SET idempotency_key = NULL
+WHERE COALESCE(created_by, 'shared') = sqlc.arg('created_by')::text AND idempotency_key = sqlc.arg('idempotency_key')::text
+  AND idempotency_expires_at <= now();
+
+-- name: GetNotificationCallback :one
+SELECT type, callback_url, callback_attempts, created_by FROM notifications
+WHERE id = $1;
@@ -0,0 +1,43 @@
+-- name: CreateOutboxMessage :one
+INSERT INTO notification_outbox (
+  notification_id,
+  task_type,
+  payload,
+  queue,
+  process_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ClaimPendingOutboxMessages :many
+SELECT * FROM notification_outbox
+WHERE status = 'pending'
+ORDER BY created_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxMessageDispatched :exec
+UPDATE notification_outbox
+SET status = 'dispatched', attempts = attempts + 1, last_error = NULL, dispatched_at = now()
+WHERE id = $1;
+
+-- name: RecordOutboxFailure :exec
+UPDATE notification_outbox
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
+
+-- name: DeleteDispatchedOutboxMessages :execrows
+DELETE FROM notification_outbox
+WHERE status = 'dispatched' AND dispatched_at < sqlc.arg('before')::timestamptz
+  -- Scheduled tasks are kept until they run, so they can still be cancelled
+  AND (process_at IS NULL OR process_at < sqlc.arg('before')::timestamptz);
+
+-- name: DeletePendingOutboxMessages :execrows
+DELETE FROM notification_outbox
+WHERE notification_id = $1 AND status = 'pending';
+
+-- name: ListOutboxMessagesByNotification :many
+SELECT * FROM notification_outbox
+WHERE notification_id = $1
+ORDER BY created_at;
@@ -0,0 +1,18 @@
+-- name: ListPreferencesByUser :many
+SELECT * FROM user_notification_preferences
+WHERE user_id = $1
+ORDER BY type_pattern;
+
+-- name: CreatePreference :one
+INSERT INTO user_notification_preferences (
+  user_id,
+  type_pattern,
+  enabled
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: DeletePreferencesByUser :exec
+DELETE FROM user_notification_preferences
+WHERE user_id = $1;
@@ -0,0 +1,25 @@
+-- name: GetQuietHours :one
+SELECT * FROM user_quiet_hours
+WHERE user_id = $1 LIMIT 1;
+
+-- name: UpsertQuietHours :one
+INSERT INTO user_quiet_hours (
+  user_id,
+  start_time,
+  end_time,
+  timezone,
+  enabled
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  start_time = EXCLUDED.start_time,
+  end_time = EXCLUDED.end_time,
+  timezone = EXCLUDED.timezone,
+  enabled = EXCLUDED.enabled,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteQuietHours :execrows
+DELETE FROM user_quiet_hours
+WHERE user_id = $1;
@@ -0,0 +1,73 @@
+-- name: GetRoutingPolicy :one
+SELECT * FROM notification_routing_policies
+WHERE type = $1 LIMIT 1;
+
+-- name: ListRoutingPolicies :many
+SELECT * FROM notification_routing_policies
+ORDER BY type;
+
+-- name: UpsertRoutingPolicy :one
+INSERT INTO notification_routing_policies (
+  type,
+  fallback_channel,
+  fallback_delay_seconds
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (type) DO UPDATE
+SET fallback_channel = EXCLUDED.fallback_channel,
+    fallback_delay_seconds = EXCLUDED.fallback_delay_seconds,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteRoutingPolicy :execrows
+DELETE FROM notification_routing_policies
+WHERE type = $1;
+
+-- name: CreateFallback :one
+INSERT INTO notification_fallbacks (
+  notification_id,
+  user_id,
+  channel,
+  delay_seconds,
+  expected_push
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (notification_id, user_id) DO UPDATE
+SET expected_push = EXCLUDED.expected_push
+RETURNING *;
+
+-- name: TriggerFallback :one
+UPDATE notification_fallbacks f
+SET status = 'triggered', deliveries = sqlc.arg('deliveries'), process_at = sqlc.arg('process_at'), triggered_at = now()
+WHERE f.notification_id = sqlc.arg('notification_id')
+  AND f.user_id = sqlc.arg('user_id')
+  AND f.status = 'pending'
+  AND NOT EXISTS (
+    SELECT 1 FROM notification_attempts a
+    WHERE a.notification_id = f.notification_id AND a.user_id = f.user_id
+      AND a.channel = 'push' AND a.status = 'delivered'
+  )
+  AND (
+    SELECT COUNT(*) FROM (
+      SELECT DISTINCT ON (a.subscription_id) a.status
+      FROM notification_attempts a
+      WHERE a.notification_id = f.notification_id AND a.user_id = f.user_id
+        AND a.channel = 'push'
+      ORDER BY a.subscription_id, a.created_at DESC
+    ) latest
+    WHERE latest.status IN ('failed', 'pruned', 'skipped')
+  ) >= f.expected_push
+RETURNING *;
+
+-- name: ListFallbacksByNotification :many
+SELECT * FROM notification_fallbacks
+WHERE notification_id = $1
+ORDER BY created_at;
+
+-- name: GetPendingFallback :one
+SELECT * FROM notification_fallbacks
+WHERE notification_id = $1 AND user_id = $2 AND status = 'pending'
+LIMIT 1
+FOR UPDATE;
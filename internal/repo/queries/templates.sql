@@ -0,0 +1,48 @@
+-- name: CreateTemplate :one
+INSERT INTO notification_templates (
+  type,
+  locale,
+  version,
+  title,
+  body,
+  url,
+  icon,
+  is_active
+) VALUES (
+  $1,
+  $2,
+  (SELECT COALESCE(MAX(t.version), 0) + 1 FROM notification_templates t WHERE t.type = $1 AND t.locale = $2),
+  $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetTemplate :one
+SELECT * FROM notification_templates
+WHERE id = $1 LIMIT 1;
+
+-- name: GetActiveTemplate :one
+SELECT * FROM notification_templates
+WHERE type = $1 AND locale = $2 AND is_active = true
+LIMIT 1;
+
+-- name: ListTemplates :many
+SELECT * FROM notification_templates
+WHERE (sqlc.narg('type')::text IS NULL OR type = sqlc.narg('type'))
+  AND (sqlc.narg('locale')::text IS NULL OR locale = sqlc.narg('locale'))
+ORDER BY type, locale, version DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: DeactivateTemplates :exec
+UPDATE notification_templates
+SET is_active = false, updated_at = now()
+WHERE type = $1 AND locale = $2 AND is_active = true;
+
+-- name: SetTemplateActive :one
+UPDATE notification_templates
+SET is_active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTemplate :exec
+DELETE FROM notification_templates
+WHERE id = $1;
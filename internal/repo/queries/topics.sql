@@ -0,0 +1,51 @@
+-- name: CreateTopic :one
+INSERT INTO notification_topics (
+  name,
+  description
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetTopic :one
+SELECT * FROM notification_topics
+WHERE name = $1 LIMIT 1;
+
+-- name: ListTopics :many
+SELECT * FROM notification_topics
+ORDER BY name
+LIMIT $1 OFFSET $2;
+
+-- name: DeleteTopic :execrows
+DELETE FROM notification_topics
+WHERE name = $1;
+
+-- name: CountExistingTopics :one
+SELECT COUNT(*) FROM notification_topics
+WHERE name = ANY(sqlc.arg('names')::text[]);
+
+-- name: SubscribeToTopic :exec
+INSERT INTO topic_subscriptions (
+  topic,
+  user_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (topic, user_id) DO NOTHING;
+
+-- name: UnsubscribeFromTopic :execrows
+DELETE FROM topic_subscriptions
+WHERE topic = $1 AND user_id = $2;
+
+-- name: ListTopicSubscribers :many
+SELECT * FROM topic_subscriptions
+WHERE topic = $1
+ORDER BY user_id
+LIMIT $2 OFFSET $3;
+
+-- name: ListTopicMembersPage :many
+SELECT DISTINCT user_id FROM topic_subscriptions
+WHERE topic = ANY(sqlc.arg('topics')::text[])
+  AND user_id > sqlc.arg('after_user_id')
+ORDER BY user_id
+LIMIT sqlc.arg('batch_size');
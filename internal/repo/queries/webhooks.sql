@@ -0,0 +1,35 @@
+-- name: CreateTopicWebhook :one
+INSERT INTO topic_webhooks (
+  topic,
+  url,
+  secret
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (topic, url) DO UPDATE
+SET is_active = true, updated_at = now()
+RETURNING *;
+
+-- name: ListTopicWebhooks :many
+SELECT * FROM topic_webhooks
+WHERE topic = $1
+ORDER BY created_at;
+
+-- name: ListActiveTopicWebhooks :many
+SELECT * FROM topic_webhooks
+WHERE topic = ANY(sqlc.arg('topics')::text[]) AND is_active = true
+ORDER BY created_at;
+
+-- name: DeleteTopicWebhook :execrows
+DELETE FROM topic_webhooks
+WHERE id = $1 AND topic = $2;
+
+-- name: GetWebhookEndpoint :one
+SELECT id, address AS url, COALESCE(secret, '')::text AS secret, is_active, ''::text AS topic
+FROM user_contact_points
+WHERE user_contact_points.id = sqlc.arg('id') AND channel = 'webhook'
+UNION ALL
+SELECT id, url, secret, is_active, topic
+FROM topic_webhooks
+WHERE topic_webhooks.id = sqlc.arg('id')
+LIMIT 1;
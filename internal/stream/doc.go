@@ -0,0 +1,3 @@
+// Package stream carries real-time inbox events from the worker to API
+// replicas over Redis pub/sub
+package stream
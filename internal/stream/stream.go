@@ -0,0 +1,144 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// channelPrefix namespaces per-user pub/sub channels; the user ID follows it
+const channelPrefix = "notifications:inbox:"
+
+// subscriberBuffer is how many events a slow stream may fall behind before
+// it is dropped; dropped clients reconnect and resume with Last-Event-ID
+const subscriberBuffer = 32
+
+// Event announces a new inbox item. Streams load the item itself, so events
+// stay small and never carry rendered copy.
+type Event struct {
+	InboxItemID    uuid.UUID `json:"inbox_item_id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	UserID         string    `json:"user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Publisher announces inbox events to every API replica
+type Publisher struct {
+	rdb *redis.Client
+}
+
+// NewPublisher creates a new publisher
+func NewPublisher(rdb *redis.Client) *Publisher {
+	return &Publisher{rdb: rdb}
+}
+
+// Publish sends an event to the user's channel. Pub/sub is fire-and-forget:
+// streams that miss it catch up from the inbox when they reconnect.
+func (p *Publisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := p.rdb.Publish(ctx, channelPrefix+event.UserID, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// Hub relays events from Redis to the streams open on this replica. It holds
+// a single pattern subscription however many streams are open.
+type Hub struct {
+	rdb    *redis.Client
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	subs map[string]map[chan Event]struct{}
+}
+
+// NewHub creates a new hub; call Run to start relaying
+func NewHub(rdb *redis.Client, logger *zap.Logger) *Hub {
+	return &Hub{
+		rdb:    rdb,
+		logger: logger,
+		subs:   make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Run relays events until ctx is cancelled. The Redis client reconnects on
+// its own; events published while disconnected are lost to open streams.
+func (h *Hub) Run(ctx context.Context) {
+	pubsub := h.rdb.PSubscribe(ctx, channelPrefix+"*")
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				h.logger.Warn("invalid stream event", zap.Error(err), zap.String("channel", msg.Channel))
+				continue
+			}
+			event.UserID = strings.TrimPrefix(msg.Channel, channelPrefix)
+			h.dispatch(event)
+		}
+	}
+}
+
+// Subscribe registers a stream for the user's events. The channel is closed
+// when the stream falls too far behind or unsubscribe is called.
+func (h *Hub) Subscribe(userID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan Event]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, ch)
+	}
+	return ch, unsubscribe
+}
+
+// dispatch hands an event to the user's streams, dropping any that are full
+func (h *Hub) dispatch(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			h.remove(event.UserID, ch)
+		}
+	}
+}
+
+// remove closes and forgets a stream's channel; h.mu must be held
+func (h *Hub) remove(userID string, ch chan Event) {
+	streams := h.subs[userID]
+	if _, ok := streams[ch]; !ok {
+		return
+	}
+	delete(streams, ch)
+	close(ch)
+	if len(streams) == 0 {
+		delete(h.subs, userID)
+	}
+}
@@ -0,0 +1,77 @@
+package stream
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for stream tokens that are malformed, expired
+// or not signed with this service's secret
+var ErrInvalidToken = errors.New("invalid stream token")
+
+type tokenClaims struct {
+	UserID    string `json:"u"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenSigner issues and verifies stream tokens, which let a browser open a
+// single user's inbox stream without signing requests. Tokens are the
+// base64url JSON claims and their HMAC-SHA256, separated by a dot; they are
+// checked only when a stream connects, so a client mints a new one before
+// reconnecting after it expires.
+type TokenSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewTokenSigner creates a new token signer whose tokens are valid for ttl
+func NewTokenSigner(secret string, ttl time.Duration) *TokenSigner {
+	return &TokenSigner{secret: []byte(secret), ttl: ttl}
+}
+
+// Issue signs a token for userID's stream and returns it with its expiry
+func (s *TokenSigner) Issue(userID string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(s.ttl).Truncate(time.Second)
+	data, err := json.Marshal(tokenClaims{UserID: userID, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to marshal claims: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload)), expiresAt, nil
+}
+
+// Verify checks a token and returns the user whose stream it opens
+func (s *TokenSigner) Verify(token string, now time.Time) (string, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(payload)) {
+		return "", ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(data, &claims); err != nil || claims.UserID == "" {
+		return "", ErrInvalidToken
+	}
+	if !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return "", ErrInvalidToken
+	}
+	return claims.UserID, nil
+}
+
+func (s *TokenSigner) sign(payload string) []byte {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
@@ -0,0 +1,103 @@
+package templates
+
+// Template holds the localized patterns for a single notification type.
+// Patterns use {{variable}} placeholders that are filled from the notification data.
+type Template struct {
+	Title string
+	Body  string
+	URL   string
+	Icon  string
+}
+
+// Catalog maps notification types to templates for a single locale.
+type Catalog map[string]Template
+
+const stockRequestURL = "/dashboards/warehouse/stock-requests/{{requestId}}"
+
+// builtinCatalogs contains the templates shipped with the service, keyed by locale.
+var builtinCatalogs = map[string]Catalog{
+	"en": {
+		"STOCK_REQUEST.NEW_REQUEST": {
+			Title: "New stock request",
+			Body:  "Request {{requestNumber}} from {{warehouse}} ({{totalItems}} items)",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.REVIEWED": {
+			Title: "Request reviewed",
+			Body:  "Request {{requestNumber}} has been reviewed",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.APPROVED": {
+			Title: "Request approved",
+			Body:  "Request {{requestNumber}} has been approved",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.PARTIALLY_APPROVED": {
+			Title: "Request partially approved",
+			Body:  "Request {{requestNumber}} has been partially approved",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.REJECTED": {
+			Title: "Request rejected",
+			Body:  "Request {{requestNumber}} has been rejected",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.FULFILLED": {
+			Title: "Request fulfilled",
+			Body:  "Request {{requestNumber}} has been fulfilled",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.CANCELLED": {
+			Title: "Request cancelled",
+			Body:  "Request {{requestNumber}} has been cancelled",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.STOCK_UNAVAILABLE": {
+			Title: "Stock unavailable",
+			Body:  "Some items in request {{requestNumber}} are out of stock at {{warehouse}}",
+			URL:   stockRequestURL,
+		},
+	},
+	"fr": {
+		"STOCK_REQUEST.NEW_REQUEST": {
+			Title: "Nouvelle demande de stock",
+			Body:  "Demande {{requestNumber}} de {{warehouse}} ({{totalItems}} articles)",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.REVIEWED": {
+			Title: "Demande examinée",
+			Body:  "La demande {{requestNumber}} a été examinée",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.APPROVED": {
+			Title: "Demande approuvée",
+			Body:  "La demande {{requestNumber}} a été approuvée",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.PARTIALLY_APPROVED": {
+			Title: "Demande partiellement approuvée",
+			Body:  "La demande {{requestNumber}} a été partiellement approuvée",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.REJECTED": {
+			Title: "Demande rejetée",
+			Body:  "La demande {{requestNumber}} a été rejetée",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.FULFILLED": {
+			Title: "Demande traitée",
+			Body:  "La demande {{requestNumber}} a été traitée",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.CANCELLED": {
+			Title: "Demande annulée",
+			Body:  "La demande {{requestNumber}} a été annulée",
+			URL:   stockRequestURL,
+		},
+		"STOCK_REQUEST.STOCK_UNAVAILABLE": {
+			Title: "Stock indisponible",
+			Body:  "Certains articles de la demande {{requestNumber}} sont en rupture à {{warehouse}}",
+			URL:   stockRequestURL,
+		},
+	},
+}
@@ -0,0 +1,257 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"notifications/internal/repo"
+)
+
+// ErrTemplateNotFound is returned when no template exists for a notification type.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// MissingVariablesError reports placeholders that had no matching data value.
+// The content is still rendered, with missing placeholders replaced by empty strings.
+type MissingVariablesError struct {
+	Variables []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("missing template variables: %s", strings.Join(e.Variables, ", "))
+}
+
+// Content is the rendered, localized copy for a notification.
+// TemplateID and TemplateVersion are set when a database template was used.
+type Content struct {
+	Locale          string
+	Title           string
+	Body            string
+	URL             string
+	Icon            string
+	TemplateID      *uuid.UUID
+	TemplateVersion *int32
+}
+
+// Resolver maps notification types and data to localized content.
+// Active database templates take precedence over the built-in catalogs.
+type Resolver struct {
+	defaultLocale string
+	catalogs      map[string]Catalog
+	repo          *repo.Repository
+}
+
+// NewResolver creates a resolver backed by the built-in catalogs and, when
+// repository is non-nil, the notification_templates table.
+func NewResolver(defaultLocale string, repository *repo.Repository) *Resolver {
+	locale := NormalizeLocale(defaultLocale)
+	if locale == "" {
+		locale = "en"
+	}
+	return &Resolver{
+		defaultLocale: locale,
+		catalogs:      builtinCatalogs,
+		repo:          repository,
+	}
+}
+
+// DefaultLocale returns the locale used when no candidate locale is supported.
+func (r *Resolver) DefaultLocale() string {
+	return r.defaultLocale
+}
+
+// ChooseLocale returns the first candidate locale that has a catalog, in order
+// of preference (e.g. request locale, then subscription locale), falling back
+// to the default locale. Regional variants fall back to their base language.
+func (r *Resolver) ChooseLocale(candidates ...*string) string {
+	for _, c := range candidates {
+		if c == nil {
+			continue
+		}
+		if locale, ok := r.supportedLocale(*c); ok {
+			return locale
+		}
+	}
+	return r.defaultLocale
+}
+
+// Resolve renders the template for notificationType using the first candidate
+// locale (in order of preference) that has one, then the default locale.
+// A *MissingVariablesError is returned alongside the content when data lacks
+// a referenced variable.
+func (r *Resolver) Resolve(ctx context.Context, notificationType string, data map[string]interface{}, locales ...*string) (*Content, error) {
+	for _, locale := range r.candidateLocales(locales) {
+		// Database templates override the built-in catalog for the same locale
+		if r.repo != nil {
+			tmpl, err := r.repo.GetActiveTemplate(ctx, repo.GetActiveTemplateParams{
+				Type:   notificationType,
+				Locale: locale,
+			})
+			if err == nil {
+				return RenderStored(tmpl, data)
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("failed to load template: %w", err)
+			}
+		}
+		if tmpl, ok := r.catalogs[locale][notificationType]; ok {
+			return Render(tmpl, locale, data)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, notificationType)
+}
+
+// ResolveNotification renders copy for a stored notification. When the
+// notification pinned a template version at creation and that version's locale
+// is the best match, the pinned version is rendered so recipients see exactly
+// the recorded copy even if a newer version has since been activated.
+func (r *Resolver) ResolveNotification(ctx context.Context, notif repo.Notification, data map[string]interface{}, locales ...*string) (*Content, error) {
+	if r.repo != nil && notif.TemplateID.Valid {
+		pinned, err := r.repo.GetTemplate(ctx, uuid.UUID(notif.TemplateID.Bytes))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to load pinned template: %w", err)
+		}
+		if err == nil {
+			for _, locale := range r.candidateLocales(locales) {
+				if locale == pinned.Locale {
+					return RenderStored(pinned, data)
+				}
+				if r.hasTemplate(ctx, notif.Type, locale) {
+					break
+				}
+			}
+		}
+	}
+	return r.Resolve(ctx, notif.Type, data, locales...)
+}
+
+// Render fills a template's placeholders from data.
+func Render(tmpl Template, locale string, data map[string]interface{}) (*Content, error) {
+	var missing []string
+	seen := make(map[string]bool)
+	render := func(pattern string) string {
+		return placeholderPattern.ReplaceAllStringFunc(pattern, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			value, ok := lookupVariable(data, name)
+			if !ok {
+				if !seen[name] {
+					seen[name] = true
+					missing = append(missing, name)
+				}
+				return ""
+			}
+			return value
+		})
+	}
+
+	content := &Content{
+		Locale: locale,
+		Title:  render(tmpl.Title),
+		Body:   render(tmpl.Body),
+		URL:    render(tmpl.URL),
+		Icon:   render(tmpl.Icon),
+	}
+	if len(missing) > 0 {
+		return content, &MissingVariablesError{Variables: missing}
+	}
+	return content, nil
+}
+
+// RenderStored renders a database template version and records its identity.
+func RenderStored(stored repo.NotificationTemplate, data map[string]interface{}) (*Content, error) {
+	tmpl := Template{Title: stored.Title, Body: stored.Body}
+	if stored.Url != nil {
+		tmpl.URL = *stored.Url
+	}
+	if stored.Icon != nil {
+		tmpl.Icon = *stored.Icon
+	}
+	content, err := Render(tmpl, stored.Locale, data)
+	id, version := stored.ID, stored.Version
+	content.TemplateID = &id
+	content.TemplateVersion = &version
+	return content, err
+}
+
+// hasTemplate reports whether any template exists for the type in locale.
+func (r *Resolver) hasTemplate(ctx context.Context, notificationType, locale string) bool {
+	if _, ok := r.catalogs[locale][notificationType]; ok {
+		return true
+	}
+	if r.repo == nil {
+		return false
+	}
+	_, err := r.repo.GetActiveTemplate(ctx, repo.GetActiveTemplateParams{Type: notificationType, Locale: locale})
+	return err == nil
+}
+
+// candidateLocales expands preferred locales into lookup keys, e.g. "fr-FR"
+// yields "fr-fr" then "fr", and always ends with the default locale.
+func (r *Resolver) candidateLocales(locales []*string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if locale != "" && !seen[locale] {
+			seen[locale] = true
+			out = append(out, locale)
+		}
+	}
+	for _, l := range locales {
+		if l == nil {
+			continue
+		}
+		normalized := NormalizeLocale(*l)
+		add(normalized)
+		if base, _, found := strings.Cut(normalized, "-"); found {
+			add(base)
+		}
+	}
+	add(r.defaultLocale)
+	return out
+}
+
+// supportedLocale maps a locale such as "fr-FR" to a catalog key ("fr-fr" or "fr").
+func (r *Resolver) supportedLocale(locale string) (string, bool) {
+	normalized := NormalizeLocale(locale)
+	if normalized == "" {
+		return "", false
+	}
+	if _, ok := r.catalogs[normalized]; ok {
+		return normalized, true
+	}
+	if base, _, found := strings.Cut(normalized, "-"); found {
+		if _, ok := r.catalogs[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// lookupVariable resolves a placeholder name against data, supporting dotted
+// paths into nested objects (e.g. {{requester.name}}).
+func lookupVariable(data map[string]interface{}, name string) (string, bool) {
+	var current interface{} = data
+	for _, part := range strings.Split(name, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		current, ok = m[part]
+		if !ok || current == nil {
+			return "", false
+		}
+	}
+	return fmt.Sprint(current), true
+}
+
+// NormalizeLocale lowercases a locale and uses "-" as the region separator.
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
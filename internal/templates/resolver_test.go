@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"notifications/internal/repo"
+)
+
+func TestRender(t *testing.T) {
+	tmpl := Template{
+		Title: "Request {{ requestNumber }}",
+		Body:  "{{requester.name}} asked {{warehouse}} for {{totalItems}} items ({{note}}, {{note}})",
+		URL:   "/requests/{{requestId}}",
+		Icon:  "/icons/{{icon}}.png",
+	}
+	data := map[string]interface{}{
+		"requestNumber": "SR-7",
+		"requester":     map[string]interface{}{"name": "Ana"},
+		"warehouse":     "North",
+		"totalItems":    3,
+		"requestId":     "abc",
+		"icon":          nil,
+	}
+
+	content, err := Render(tmpl, "en", data)
+	var missing *MissingVariablesError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Render() error = %v, want *MissingVariablesError", err)
+	}
+	if want := []string{"note", "icon"}; !reflect.DeepEqual(missing.Variables, want) {
+		t.Errorf("missing variables = %v, want %v", missing.Variables, want)
+	}
+	want := &Content{
+		Locale: "en",
+		Title:  "Request SR-7",
+		Body:   "Ana asked North for 3 items (, )",
+		URL:    "/requests/abc",
+		Icon:   "/icons/.png",
+	}
+	if !reflect.DeepEqual(content, want) {
+		t.Errorf("Render() = %+v, want %+v", content, want)
+	}
+
+	data["note"], data["icon"] = "urgent", "box"
+	if _, err := Render(tmpl, "en", data); err != nil {
+		t.Errorf("Render() with every variable error = %v", err)
+	}
+}
+
+func TestResolveLocaleChain(t *testing.T) {
+	r := NewResolver("en", nil)
+	locale := func(s string) *string { return &s }
+	data := map[string]interface{}{"requestNumber": "SR-7", "warehouse": "North", "totalItems": 3, "requestId": "abc"}
+
+	tests := []struct {
+		name         string
+		request      *string
+		subscription *string
+		want         string
+	}{
+		{"request locale", locale("fr"), locale("en"), "fr"},
+		{"regional request locale", locale("fr_CA"), nil, "fr"},
+		{"subscription when request unsupported", locale("de"), locale("fr-FR"), "fr"},
+		{"subscription without request", nil, locale("FR"), "fr"},
+		{"default", locale("de"), locale("it"), "en"},
+		{"none", nil, nil, "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := r.Resolve(context.Background(), "STOCK_REQUEST.NEW_REQUEST", data, tt.request, tt.subscription)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if content.Locale != tt.want {
+				t.Errorf("Resolve() locale = %q, want %q", content.Locale, tt.want)
+			}
+			if want := builtinCatalogs[tt.want]["STOCK_REQUEST.NEW_REQUEST"].Title; content.Title != want {
+				t.Errorf("Resolve() title = %q, want %q", content.Title, want)
+			}
+			if got := r.ChooseLocale(tt.request, tt.subscription); got != tt.want {
+				t.Errorf("ChooseLocale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := r.Resolve(context.Background(), "UNKNOWN", data); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Resolve() unknown type error = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestResolveNotificationVersion(t *testing.T) {
+	v1 := storedTemplate("STOCK_REQUEST.APPROVED", "en", 1, "Approved v1 {{requestNumber}}", false)
+	v2 := storedTemplate("STOCK_REQUEST.APPROVED", "en", 2, "Approved v2 {{requestNumber}}", true)
+	r := NewResolver("en", &repo.Repository{Queries: repo.New(fakeTemplateDB{v1, v2})})
+	data := map[string]interface{}{"requestNumber": "SR-7", "requestId": "abc"}
+	fr := "fr"
+
+	tests := []struct {
+		name        string
+		pinned      pgtype.UUID
+		locale      *string
+		wantTitle   string
+		wantVersion *int32
+	}{
+		{"active version", pgtype.UUID{}, nil, "Approved v2 SR-7", &v2.Version},
+		{"pinned version", pgtype.UUID{Bytes: v1.ID, Valid: true}, nil, "Approved v1 SR-7", &v1.Version},
+		{"pinned version in another locale", pgtype.UUID{Bytes: v1.ID, Valid: true}, &fr, "Demande approuvée", nil},
+		{"pinned version deleted", pgtype.UUID{Bytes: uuid.New(), Valid: true}, nil, "Approved v2 SR-7", &v2.Version},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notif := repo.Notification{Type: "STOCK_REQUEST.APPROVED", TemplateID: tt.pinned}
+			content, err := r.ResolveNotification(context.Background(), notif, data, tt.locale)
+			if err != nil {
+				t.Fatalf("ResolveNotification() error = %v", err)
+			}
+			if content.Title != tt.wantTitle {
+				t.Errorf("ResolveNotification() title = %q, want %q", content.Title, tt.wantTitle)
+			}
+			if !reflect.DeepEqual(content.TemplateVersion, tt.wantVersion) {
+				t.Errorf("ResolveNotification() version = %v, want %v", content.TemplateVersion, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func storedTemplate(notificationType, locale string, version int32, title string, active bool) repo.NotificationTemplate {
+	return repo.NotificationTemplate{
+		ID:       uuid.New(),
+		Type:     notificationType,
+		Locale:   locale,
+		Version:  version,
+		Title:    title,
+		Body:     "Request {{requestNumber}}",
+		IsActive: active,
+	}
+}
+
+// fakeTemplateDB answers the GetTemplate and GetActiveTemplate queries from
+// a fixed set of template versions
+type fakeTemplateDB []repo.NotificationTemplate
+
+func (db fakeTemplateDB) QueryRow(_ context.Context, _ string, args ...interface{}) pgx.Row {
+	for _, tmpl := range db {
+		switch {
+		case len(args) == 1 && args[0] == tmpl.ID:
+			return templateRow{tmpl: tmpl}
+		case len(args) == 2 && tmpl.IsActive && args[0] == tmpl.Type && args[1] == tmpl.Locale:
+			return templateRow{tmpl: tmpl}
+		}
+	}
+	return templateRow{err: pgx.ErrNoRows}
+}
+
+func (fakeTemplateDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (fakeTemplateDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (fakeTemplateDB) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, errors.New("unexpected copy")
+}
+
+type templateRow struct {
+	tmpl repo.NotificationTemplate
+	err  error
+}
+
+func (r templateRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*uuid.UUID) = r.tmpl.ID
+	*dest[1].(*string) = r.tmpl.Type
+	*dest[2].(*string) = r.tmpl.Locale
+	*dest[3].(*int32) = r.tmpl.Version
+	*dest[4].(*string) = r.tmpl.Title
+	*dest[5].(*string) = r.tmpl.Body
+	*dest[6].(**string) = r.tmpl.Url
+	*dest[7].(**string) = r.tmpl.Icon
+	*dest[8].(*bool) = r.tmpl.IsActive
+	return nil
+}
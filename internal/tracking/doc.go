@@ -0,0 +1,3 @@
+// Package tracking signs the links and tokens that let recipients' clients
+// report clicks and displays without API credentials
+package tracking
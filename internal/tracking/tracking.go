@@ -0,0 +1,131 @@
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ClickPath is the public redirect endpoint tracked links point at; the
+// token follows it
+const ClickPath = "/v1/t/"
+
+// EventsPath is the public endpoint clients report events to
+const EventsPath = "/v1/events"
+
+// ErrInvalidToken is returned for tokens that are malformed or not signed
+// with this service's secret
+var ErrInvalidToken = errors.New("invalid tracking token")
+
+// Claims identify the recipient a token was issued to. URL is set on click
+// tokens and is where the redirect sends the recipient.
+type Claims struct {
+	NotificationID uuid.UUID `json:"n"`
+	UserID         string    `json:"u"`
+	URL            string    `json:"r,omitempty"`
+}
+
+// Signer issues and verifies tracking tokens. Tokens are the base64url JSON
+// claims and their HMAC-SHA256, separated by a dot; they don't expire, since
+// recipients may act on a notification long after it arrives.
+type Signer struct {
+	secret     []byte
+	baseURL    string
+	appBaseURL *url.URL
+}
+
+// NewSigner creates a new signer. baseURL is the API's public address that
+// tracked links and the events endpoint are built on. appBaseURL is the web
+// app's address that relative notification URLs resolve against; when it is
+// empty, relative URLs can't be tracked.
+func NewSigner(secret, baseURL, appBaseURL string) *Signer {
+	s := &Signer{
+		secret:  []byte(secret),
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+	if u, err := url.Parse(appBaseURL); err == nil && isAbsoluteURL(u) {
+		s.appBaseURL = u
+	}
+	return s
+}
+
+// Token signs claims
+func (s *Signer) Token(claims Claims) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal claims: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload)), nil
+}
+
+// Parse verifies a token and returns its claims
+func (s *Signer) Parse(token string) (Claims, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(payload)) {
+		return Claims{}, ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ClickURL returns a link that records a click by the recipient, then
+// redirects to target
+func (s *Signer) ClickURL(notificationID uuid.UUID, userID, target string) (string, error) {
+	token, err := s.Token(Claims{NotificationID: notificationID, UserID: userID, URL: target})
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + ClickPath + token, nil
+}
+
+// Target returns the absolute URL a tracked link to raw should redirect to:
+// raw itself when it is an absolute http(s) URL, or raw resolved against the
+// app base URL when it is relative. It returns false when raw can't be
+// tracked and should be left as it is.
+func (s *Signer) Target(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if isAbsoluteURL(u) {
+		return raw, true
+	}
+	if u.Scheme != "" || u.Host != "" || s.appBaseURL == nil {
+		return "", false
+	}
+	return s.appBaseURL.ResolveReference(u).String(), true
+}
+
+func isAbsoluteURL(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// EventsURL returns the absolute URL of the events endpoint
+func (s *Signer) EventsURL() string {
+	return s.baseURL + EventsPath
+}
+
+func (s *Signer) sign(payload string) []byte {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
@@ -0,0 +1,2 @@
+// Package webhook delivers notifications as signed HTTPS POSTs
+package webhook
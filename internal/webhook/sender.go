@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"notifications/internal/auth"
+	"notifications/internal/channel"
+	"notifications/internal/repo"
+	"notifications/internal/templates"
+)
+
+// Sender handles sending notifications to webhook endpoints: webhook contact
+// points of a recipient, and topic webhooks
+type Sender struct {
+	repo     *repo.Repository
+	resolver *templates.Resolver
+	client   *http.Client
+}
+
+// NewSender creates a new webhook sender. timeout bounds each POST.
+func NewSender(repository *repo.Repository, resolver *templates.Resolver, timeout time.Duration) *Sender {
+	return &Sender{
+		repo:     repository,
+		resolver: resolver,
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect could send the signed payload somewhere unexpected
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// SendNotification POSTs a notification to a webhook endpoint. The request
+// carries X-Timestamp and X-Signature headers computed with auth.Sign and the
+// endpoint's secret, so receivers can verify it with auth.Verify.
+func (s *Sender) SendNotification(
+	ctx context.Context,
+	notificationID uuid.UUID,
+	endpointID uuid.UUID,
+	userID string,
+) (*channel.DeliveryResult, error) {
+	startTime := time.Now()
+
+	endpoint, err := s.repo.GetWebhookEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	if !endpoint.IsActive {
+		return &channel.DeliveryResult{
+			Success: false,
+			Error:   "webhook endpoint is not active",
+			Skipped: true,
+		}, nil
+	}
+
+	notif, err := s.repo.GetNotification(ctx, notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	// Missing template variables render as empty strings and don't block delivery
+	body, content, err := BuildPayload(ctx, s.resolver, notif, userID, endpoint.Topic)
+	var missing *templates.MissingVariablesError
+	if err != nil && !errors.As(err, &missing) {
+		return &channel.DeliveryResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to build payload: %v", err),
+		}, nil
+	}
+
+	target, err := url.Parse(endpoint.Url)
+	if err != nil {
+		return &channel.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Sprintf("invalid webhook url: %v", err),
+			Permanent: true,
+		}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notification-service-webhook")
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Signature", auth.Sign([]byte(endpoint.Secret), http.MethodPost, target.Path, body, timestamp))
+	req.Header.Set("X-Notification-ID", notif.ID.String())
+	req.Header.Set("X-Webhook-ID", endpoint.ID.String())
+
+	resp, err := s.client.Do(req)
+
+	result := &channel.DeliveryResult{
+		LatencyMs:       int(time.Since(startTime).Milliseconds()),
+		TemplateID:      content.TemplateID,
+		TemplateVersion: content.TemplateVersion,
+	}
+
+	if err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		return result, nil
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	result.HTTPStatus = resp.StatusCode
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		result.Success = true
+
+	case resp.StatusCode == http.StatusTooManyRequests: // 429
+		result.Success = false
+		result.Error = "rate limited (429)"
+		result.RetryAfter = channel.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		// Redirects aren't followed, and won't go away on retry
+		result.Success = false
+		result.Error = fmt.Sprintf("unexpected redirect: %d", resp.StatusCode)
+		result.Permanent = true
+
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// Classified by status: 400/401/403/413 are permanent
+		result.Success = false
+		result.Error = fmt.Sprintf("client error: %d", resp.StatusCode)
+
+	default:
+		// Server error - temporary failure, should retry
+		result.Success = false
+		result.Error = fmt.Sprintf("server error: %d", resp.StatusCode)
+	}
+
+	return result, nil
+}
+
+// Payload is the JSON body POSTed to webhook endpoints
+type Payload struct {
+	NotificationID uuid.UUID              `json:"notification_id"`
+	Type           string                 `json:"type"`
+	UserID         string                 `json:"user_id,omitempty"` // Set for a recipient's webhook
+	Topic          string                 `json:"topic,omitempty"`   // Set for a topic webhook
+	Locale         string                 `json:"locale"`
+	Title          string                 `json:"title,omitempty"`
+	Body           string                 `json:"body,omitempty"`
+	Icon           string                 `json:"icon,omitempty"`
+	URL            string                 `json:"url,omitempty"`
+	Data           map[string]interface{} `json:"data,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// BuildPayload renders the webhook body for a notification. Copy comes from
+// the same templates and explicit overrides as the push payload (see
+// webpush.BuildPayload), in the notification's or the default locale.
+// The content it was rendered from is returned too. A
+// *templates.MissingVariablesError is returned alongside a usable payload
+// when the data lacks template variables.
+func BuildPayload(ctx context.Context, resolver *templates.Resolver, notif repo.Notification, userID, topic string) ([]byte, *templates.Content, error) {
+	var data map[string]interface{}
+	if len(notif.Data) > 0 {
+		_ = json.Unmarshal(notif.Data, &data)
+	}
+
+	content, renderErr := resolver.ResolveNotification(ctx, notif, data, notif.Locale, nil)
+	var missing *templates.MissingVariablesError
+	if renderErr != nil && !errors.As(renderErr, &missing) {
+		if !errors.Is(renderErr, templates.ErrTemplateNotFound) {
+			return nil, nil, renderErr
+		}
+		renderErr = nil
+	}
+	if content == nil {
+		content = &templates.Content{Locale: resolver.ChooseLocale(notif.Locale, nil)}
+	}
+
+	b, err := json.Marshal(Payload{
+		NotificationID: notif.ID,
+		Type:           notif.Type,
+		UserID:         userID,
+		Topic:          topic,
+		Locale:         content.Locale,
+		Title:          channel.FirstNonEmpty(notif.Title, content.Title),
+		Body:           channel.FirstNonEmpty(notif.Body, content.Body),
+		Icon:           channel.FirstNonEmpty(notif.Icon, content.Icon),
+		URL:            channel.FirstNonEmpty(notif.Url, content.URL),
+		Data:           data,
+		CreatedAt:      notif.CreatedAt,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, content, renderErr
+}
+
+// NewSecret generates a random signing secret for a webhook endpoint
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidateURL checks that raw is an absolute HTTPS URL without credentials
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return errors.New("url must be an absolute https URL")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	return nil
+}
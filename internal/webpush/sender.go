@@ -3,6 +3,7 @@ package webpush
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -10,7 +11,10 @@ import (
 	webpush "github.com/SherClockHolmes/webpush-go"
 	"github.com/google/uuid"
 
+	"notifications/internal/channel"
 	"notifications/internal/repo"
+	"notifications/internal/templates"
+	"notifications/internal/tracking"
 )
 
 // Sender handles sending Web Push notifications
@@ -18,33 +22,29 @@ type Sender struct {
 	vapidPublicKey  string
 	vapidPrivateKey string
 	repo            *repo.Repository
+	resolver        *templates.Resolver
+	tracker         *tracking.Signer
 }
 
-// NewSender creates a new Web Push sender
-func NewSender(vapidPublicKey, vapidPrivateKey string, repository *repo.Repository) *Sender {
+// NewSender creates a new Web Push sender. A nil tracker sends payloads
+// without click and display tracking.
+func NewSender(vapidPublicKey, vapidPrivateKey string, repository *repo.Repository, resolver *templates.Resolver, tracker *tracking.Signer) *Sender {
 	return &Sender{
 		vapidPublicKey:  vapidPublicKey,
 		vapidPrivateKey: vapidPrivateKey,
 		repo:            repository,
+		resolver:        resolver,
+		tracker:         tracker,
 	}
 }
 
-// DeliveryResult contains the outcome of a push delivery attempt
-type DeliveryResult struct {
-	Success     bool
-	HTTPStatus  int
-	LatencyMs   int
-	Error       string
-	ShouldPrune bool // True if subscription should be removed (410 Gone)
-}
-
 // SendNotification sends a push notification to a specific subscription
 func (s *Sender) SendNotification(
 	ctx context.Context,
 	notificationID uuid.UUID,
 	subscriptionID uuid.UUID,
 	userID string,
-) (*DeliveryResult, error) {
+) (*channel.DeliveryResult, error) {
 	startTime := time.Now()
 
 	// Get the subscription details
@@ -55,10 +55,11 @@ func (s *Sender) SendNotification(
 
 	// Check if subscription is active
 	if !sub.IsActive {
-		return &DeliveryResult{
+		return &channel.DeliveryResult{
 			Success:     false,
 			Error:       "subscription is not active",
 			ShouldPrune: false,
+			Skipped:     true,
 		}, nil
 	}
 
@@ -68,12 +69,27 @@ func (s *Sender) SendNotification(
 		return nil, fmt.Errorf("failed to get notification: %w", err)
 	}
 
-	// Build the push payload
-	payload, err := s.buildPayload(notif)
-	if err != nil {
-		return &DeliveryResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to build payload: %v", err),
+	// Build the push payload, rendered for this subscription's locale.
+	// Missing template variables render as empty strings and don't block delivery.
+	payload, content, err := BuildPayload(ctx, s.resolver, notif, sub.Locale, s.tracker, userID)
+	var missing *templates.MissingVariablesError
+	if err != nil && !errors.As(err, &missing) {
+		return &channel.DeliveryResult{
+			Success:   false,
+			Error:     fmt.Sprintf("failed to build payload: %v", err),
+			Permanent: true,
+		}, nil
+	}
+
+	// Push services reject payloads that don't fit in one record, so there's
+	// no point sending one that is too large
+	if len(payload) > MaxPayloadSize {
+		return &channel.DeliveryResult{
+			Success:         false,
+			Error:           fmt.Sprintf("payload is %d bytes, exceeding the %d byte Web Push limit", len(payload), MaxPayloadSize),
+			Permanent:       true,
+			TemplateID:      content.TemplateID,
+			TemplateVersion: content.TemplateVersion,
 		}, nil
 	}
 
@@ -105,8 +121,10 @@ func (s *Sender) SendNotification(
 	latencyMs := int(time.Since(startTime).Milliseconds())
 
 	// Handle response
-	result := &DeliveryResult{
-		LatencyMs: latencyMs,
+	result := &channel.DeliveryResult{
+		LatencyMs:       latencyMs,
+		TemplateID:      content.TemplateID,
+		TemplateVersion: content.TemplateVersion,
 	}
 
 	if err != nil {
@@ -136,6 +154,12 @@ func (s *Sender) SendNotification(
 		result.Error = "subscription not found (404)"
 		result.ShouldPrune = true
 
+	case resp.StatusCode == http.StatusTooManyRequests: // 429
+		// Rate limited - retry after the delay the push service asked for
+		result.Success = false
+		result.Error = "rate limited (429)"
+		result.RetryAfter = channel.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
 	case resp.StatusCode >= 400 && resp.StatusCode < 500:
 		// Client error - likely permanent failure
 		result.Success = false
@@ -156,37 +180,91 @@ func (s *Sender) SendNotification(
 	return result, nil
 }
 
-// buildPayload creates the JSON payload for the push notification
-func (s *Sender) buildPayload(notif repo.Notification) ([]byte, error) {
+// MaxPayloadSize is the largest plaintext payload that fits in a single
+// 4096-byte aes128gcm record once encryption headers and padding are added.
+const MaxPayloadSize = 3993
+
+// BuildPayload creates the JSON payload for the push notification as it would
+// be delivered to a subscription with the given locale.
+// Copy is rendered from the notification type's template in the locale chosen
+// from the notification, then the subscription, then the default locale.
+// Explicit title/body/icon/url on the notification take priority over the template.
+// A *templates.MissingVariablesError is returned alongside a usable payload
+// when the data lacks template variables.
+// With a tracker, url becomes a signed redirect that records the recipient's
+// click, and tracking_token and events_url let the service worker report
+// shown and closed events.
+func BuildPayload(
+	ctx context.Context,
+	resolver *templates.Resolver,
+	notif repo.Notification,
+	subscriptionLocale *string,
+	tracker *tracking.Signer,
+	userID string,
+) ([]byte, *templates.Content, error) {
 	payload := map[string]interface{}{
 		"notification_id": notif.ID.String(),
 		"type":            notif.Type,
 	}
 
-	// Add optional fields
-	if notif.Title != nil {
-		payload["title"] = *notif.Title
+	// Add custom data
+	var data map[string]interface{}
+	if len(notif.Data) > 0 {
+		if err := json.Unmarshal(notif.Data, &data); err == nil {
+			payload["data"] = data
+		}
 	}
-	if notif.Body != nil {
-		payload["body"] = *notif.Body
+
+	// Render the template; missing variables render as empty strings
+	content, renderErr := resolver.ResolveNotification(ctx, notif, data, notif.Locale, subscriptionLocale)
+	var missing *templates.MissingVariablesError
+	if renderErr != nil && !errors.As(renderErr, &missing) {
+		if !errors.Is(renderErr, templates.ErrTemplateNotFound) {
+			return nil, nil, renderErr
+		}
+		renderErr = nil
 	}
-	if notif.Icon != nil {
-		payload["icon"] = *notif.Icon
+	if content == nil {
+		content = &templates.Content{Locale: resolver.ChooseLocale(notif.Locale, subscriptionLocale)}
 	}
-	if notif.Url != nil {
-		payload["url"] = *notif.Url
+	payload["locale"] = content.Locale
+
+	// Add optional fields
+	if title := channel.FirstNonEmpty(notif.Title, content.Title); title != "" {
+		payload["title"] = title
 	}
-	if notif.Locale != nil {
-		payload["locale"] = *notif.Locale
+	if body := channel.FirstNonEmpty(notif.Body, content.Body); body != "" {
+		payload["body"] = body
 	}
-
-	// Add custom data
-	if len(notif.Data) > 0 {
-		var data map[string]interface{}
-		if err := json.Unmarshal(notif.Data, &data); err == nil {
-			payload["data"] = data
+	if icon := channel.FirstNonEmpty(notif.Icon, content.Icon); icon != "" {
+		payload["icon"] = icon
+	}
+	if url := channel.FirstNonEmpty(notif.Url, content.URL); url != "" {
+		// Relative URLs go through the redirect resolved against the app base
+		// URL; without one they're left for the service worker's origin
+		if tracker != nil {
+			if target, ok := tracker.Target(url); ok {
+				tracked, err := tracker.ClickURL(notif.ID, userID, target)
+				if err != nil {
+					return nil, nil, err
+				}
+				url = tracked
+			}
 		}
+		payload["url"] = url
+	}
+	if tracker != nil {
+		token, err := tracker.Token(tracking.Claims{NotificationID: notif.ID, UserID: userID})
+		if err != nil {
+			return nil, nil, err
+		}
+		payload["tracking_token"] = token
+		payload["events_url"] = tracker.EventsURL()
 	}
 
-	return json.Marshal(payload)
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, content, renderErr
 }
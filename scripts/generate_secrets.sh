@@ -1,7 +1,8 @@
 #!/usr/bin/env bash
 set -euo pipefail
 
-# Generates VAPID keys and an HMAC secret for local development
+# Generates VAPID keys, an HMAC secret and the API client secret key for local
+# development
 # Usage: ./scripts/generate_secrets.sh
 
 have_cmd() { command -v "$1" >/dev/null 2>&1; }
@@ -31,10 +32,18 @@ else
   HMAC_SECRET=$(head -c 32 /dev/urandom | base64)
 fi
 
+# Generate the key API client secrets are encrypted with
+if have_cmd openssl; then
+  API_CLIENT_SECRET_KEY=$(openssl rand -base64 32)
+else
+  API_CLIENT_SECRET_KEY=$(head -c 32 /dev/urandom | base64)
+fi
+
 cat <<EOF
 # --- Add these to your .env ---
 VAPID_PUBLIC_KEY=${VAPID_PUBLIC_KEY}
 VAPID_PRIVATE_KEY=${VAPID_PRIVATE_KEY}
 HMAC_SECRET=${HMAC_SECRET}
+API_CLIENT_SECRET_KEY=${API_CLIENT_SECRET_KEY}
 # ------------------------------
 EOF